@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/operator-framework/operator-sdk/pkg/log/zap"
+	"github.com/spf13/pflag"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+
+	"github.com/JAORMX/selinux-operator/pkg/apis"
+	"github.com/JAORMX/selinux-operator/pkg/avc"
+	"github.com/JAORMX/selinux-operator/pkg/collector"
+	"github.com/JAORMX/selinux-operator/version"
+)
+
+var log = logf.Log.WithName("cmd")
+
+func printVersion() {
+	log.Info(fmt.Sprintf("Operator Version: %s", version.Version))
+	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
+	log.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
+}
+
+func main() {
+	auditLog := pflag.String("audit-log", "/var/log/audit/audit.log", "The audit log to read the AVC denials from")
+	fromStart := pflag.Bool("from-start", false, "Read the denials that are already in the audit log")
+	procPath := pflag.String("proc", "/proc", "Where the processes of the node are found")
+	nodeName := pflag.String("node-name", os.Getenv("NODE_NAME"), "The name of the node the collector runs on")
+	interval := pflag.Duration("flush-interval", 10*time.Second, "How often the denials are published")
+	qps := pflag.Float32("qps", 5, "The number of denial objects that can be written per second")
+	burst := pflag.Int("burst", 10, "The number of denial objects that can be written at once")
+
+	pflag.CommandLine.AddFlagSet(zap.FlagSet())
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	logf.SetLogger(zap.Logger())
+
+	printVersion()
+
+	if *nodeName == "" {
+		log.Info("The node name must be set through --node-name or NODE_NAME")
+		os.Exit(1)
+	}
+
+	// Get a config to talk to the apiserver
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	scheme := clientgoscheme.Scheme
+	if err := apis.AddToScheme(scheme); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	stop := signals.SetupSignalHandler()
+	lines := make(chan string, 100)
+	go collector.New(c, scheme, *nodeName, *procPath, *qps, *burst).Run(lines, *interval, stop)
+
+	log.Info("Collecting AVC denials", "audit-log", *auditLog, "node", *nodeName)
+	if err := avc.Follow(*auditLog, *fromStart, lines, stop); err != nil {
+		log.Error(err, "Failed to read the audit log")
+		os.Exit(1)
+	}
+}
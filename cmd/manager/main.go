@@ -14,26 +14,29 @@ import (
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 	"github.com/spf13/pflag"
 	v1 "k8s.io/api/core/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	"github.com/JAORMX/selinux-operator/pkg/apis"
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
 	"github.com/JAORMX/selinux-operator/pkg/controller"
 	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
 	"github.com/JAORMX/selinux-operator/pkg/webhook"
 	"github.com/JAORMX/selinux-operator/version"
 )
 
-// Change below variables to serve metrics on different host or port.
+// Change below variable to serve metrics on a different host. The ports are
+// read from the operator settings.
 var (
-	metricsHost               = "0.0.0.0"
-	metricsPort         int32 = 8383
-	operatorMetricsPort int32 = 8686
+	metricsHost = "0.0.0.0"
 )
 var log = logf.Log.WithName("cmd")
 
@@ -67,8 +70,6 @@ func main() {
 
 	printVersion()
 
-	namespace := utils.GetOperatorNamespace()
-
 	// Get a config to talk to the apiserver
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -76,6 +77,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Read the operator settings before anything else, since some of
+	// them are needed to set up the manager.
+	operatorCfg, err := loadOperatorConfig(cfg)
+	if err != nil {
+		log.Info("Could not read the operator settings, using the defaults", "error", err.Error())
+		operatorCfg = operatorconfig.Get()
+	}
+	metricsPort := operatorCfg.MetricsPort
+	operatorMetricsPort := operatorCfg.OperatorMetricsPort
+
+	namespace := utils.GetOperatorNamespace()
+
 	ctx := context.TODO()
 
 	// Create a new Cmd to provide shared dependencies and start components
@@ -109,7 +122,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = serveCRMetrics(cfg); err != nil {
+	if err = serveCRMetrics(cfg, operatorMetricsPort); err != nil {
 		log.Info("Could not generate and serve custom resource metrics", "error", err.Error())
 	}
 
@@ -146,9 +159,23 @@ func main() {
 	}
 }
 
+// loadOperatorConfig reads the operator settings straight from the apiserver,
+// as the manager's cache isn't running yet.
+func loadOperatorConfig(cfg *rest.Config) (*selinuxv1alpha1.SelinuxOperatorConfigSpec, error) {
+	scheme := k8sruntime.NewScheme()
+	if err := apis.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	return operatorconfig.Fetch(context.TODO(), c)
+}
+
 // serveCRMetrics gets the Operator/CustomResource GVKs and generates metrics based on those types.
 // It serves those metrics on "http://metricsHost:operatorMetricsPort".
-func serveCRMetrics(cfg *rest.Config) error {
+func serveCRMetrics(cfg *rest.Config, operatorMetricsPort int32) error {
 	// Below function returns filtered operator/CustomResource specific GVKs.
 	// For more control override the below GVK list with your own custom logic.
 	filteredGVK, err := k8sutil.GetGVKsFromAddToScheme(apis.AddToScheme)
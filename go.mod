@@ -6,6 +6,7 @@ require (
 	github.com/go-logr/logr v0.1.0
 	github.com/operator-framework/operator-sdk v0.13.1-0.20191213201036-add5f7ab6014
 	github.com/spf13/pflag v1.0.5
+	gomodules.xyz/jsonpatch/v2 v2.0.1
 	k8s.io/api v0.0.0
 	k8s.io/apimachinery v0.0.0
 	k8s.io/client-go v12.0.0+incompatible
@@ -0,0 +1,79 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxDenialSpec defines the access that was denied
+type SelinuxDenialSpec struct {
+	// The SelinuxPolicy, in the same namespace, whose process type was
+	// denied the access
+	Policy string `json:"policy"`
+	// The node the denials happened on
+	NodeName string `json:"nodeName"`
+	// The type of the process that was denied the access
+	Source string `json:"source"`
+	// The type of the object the access was denied on
+	Target string `json:"target"`
+	// The class of the object the access was denied on
+	Class string `json:"class"`
+}
+
+// SelinuxDenialStatus defines the observed state of SelinuxDenial
+type SelinuxDenialStatus struct {
+	// The permissions that were denied
+	Permissions []string `json:"permissions,omitempty"`
+	// The number of times the access was denied
+	Count int64 `json:"count,omitempty"`
+	// When the access was first denied
+	FirstTimestamp metav1.Time `json:"firstTimestamp,omitempty"`
+	// When the access was last denied
+	LastTimestamp metav1.Time `json:"lastTimestamp,omitempty"`
+	// The pod that was last denied the access, if it could be found
+	Pod string `json:"pod,omitempty"`
+	// The container that was last denied the access, if it could be
+	// found
+	Container string `json:"container,omitempty"`
+	// The command that was last denied the access
+	Comm string `json:"comm,omitempty"`
+	// The name of the object the access was last denied on
+	Name string `json:"name,omitempty"`
+	// The MCS level of the process that was last denied the access
+	Level string `json:"level,omitempty"`
+	// Whether the access was allowed anyway, because the policy is
+	// permissive
+	Permissive bool `json:"permissive,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxDenial is the Schema for the selinuxdenials API. The objects are
+// published by the AVC collector running on the nodes, and aggregate the
+// denials of the same access for a policy on a node.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=selinuxdenials,scope=Namespaced
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.spec.policy`
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=`.spec.target`
+// +kubebuilder:printcolumn:name="Class",type="string",JSONPath=`.spec.class`
+// +kubebuilder:printcolumn:name="Count",type="integer",JSONPath=`.status.count`
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=`.spec.nodeName`
+type SelinuxDenial struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SelinuxDenialSpec   `json:"spec,omitempty"`
+	Status SelinuxDenialStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxDenialList contains a list of SelinuxDenial
+type SelinuxDenialList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxDenial `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxDenial{}, &SelinuxDenialList{})
+}
@@ -0,0 +1,199 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxOperatorConfigSpec defines the settings of the operator. Any
+// setting that's left empty takes its default value.
+type SelinuxOperatorConfigSpec struct {
+	// The image used by the pods that install the policies on the nodes.
+	// Defaults to quay.io/jaosorior/udica
+	InstallerImage string `json:"installerImage,omitempty"`
+	// The compute resources of the containers installing the policies
+	InstallerResources corev1.ResourceRequirements `json:"installerResources,omitempty"`
+	// The priority class of the pods installing the policies
+	InstallerPriorityClassName string `json:"installerPriorityClassName,omitempty"`
+	// The tolerations of the pods installing the policies. Defaults to
+	// tolerating the master nodes' taint.
+	InstallerTolerations []corev1.Toleration `json:"installerTolerations,omitempty"`
+	// The secrets used to pull the installer image
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// The version of the udica templates that gets installed on the
+	// nodes. Defaults to the latest version that the operator ships.
+	TemplateVersion string `json:"templateVersion,omitempty"`
+	// The namespace the operator works in when it can't detect the one
+	// it's running in. Defaults to openshift-selinux-operator
+	FallbackNamespace string `json:"fallbackNamespace,omitempty"`
+	// The port the webhook server listens on. Defaults to 8443
+	WebhookPort int32 `json:"webhookPort,omitempty"`
+	// The directory holding the webhook server's certificate and key.
+	// Defaults to /tmp/k8s-webhook-server/serving-certs
+	WebhookCertDir string `json:"webhookCertDir,omitempty"`
+	// Where the webhook server's certificate comes from. Can be operator,
+	// for a certificate that the operator issues from its own CA and
+	// renews before it expires, or external, for a certificate that's
+	// provided in the certificate directory. Defaults to operator.
+	// +kubebuilder:validation:Enum=operator;external
+	WebhookCertificates WebhookCertificateSource `json:"webhookCertificates,omitempty"`
+	// The PEM-encoded CA bundle that external webhook certificates are
+	// verified with. If it's empty, the caBundle of the webhook
+	// configurations is left as it is, so another tool can inject it.
+	WebhookCABundle string `json:"webhookCABundle,omitempty"`
+	// The port serving the operator's metrics. Defaults to 8383. Only
+	// read when the operator starts.
+	MetricsPort int32 `json:"metricsPort,omitempty"`
+	// The port serving the custom resource metrics. Defaults to 8686.
+	// Only read when the operator starts.
+	OperatorMetricsPort int32 `json:"operatorMetricsPort,omitempty"`
+	// The settings of the linter that reviews the policies for overly
+	// broad or dangerous rules.
+	Lint LintSettings `json:"lint,omitempty"`
+	// The settings of the approval workflow for policies.
+	Approval ApprovalSettings `json:"approval,omitempty"`
+	// The settings for verifying the signatures of policies.
+	Signing SigningSettings `json:"signing,omitempty"`
+	// The settings for admitting the pods that use a policy.
+	PodAdmission PodAdmissionSettings `json:"podAdmission,omitempty"`
+}
+
+// UninstalledPolicyAction defines what happens to the pods created with a
+// policy that isn't installed.
+type UninstalledPolicyAction string
+
+const (
+	// The pods are rejected
+	UninstalledPolicyDeny UninstalledPolicyAction = "deny"
+	// The pods are admitted with a warning
+	UninstalledPolicyWarn UninstalledPolicyAction = "warn"
+	// The pods are admitted, and wait for the policy to be installed on
+	// their node
+	UninstalledPolicyAllow UninstalledPolicyAction = "allow"
+)
+
+// PolicyAvailabilityMode defines how the pods that use a policy wait for
+// it to be installed on their node.
+type PolicyAvailabilityMode string
+
+const (
+	// The pods are only scheduled onto the nodes where the policy is
+	// installed
+	PolicyAvailabilityNodeAffinity PolicyAvailabilityMode = "nodeAffinity"
+	// The pods are scheduled onto any node, and aren't ready until the
+	// policy is installed on it
+	PolicyAvailabilityReadinessGate PolicyAvailabilityMode = "readinessGate"
+)
+
+// PodAdmissionSettings defines how the pods that use a policy are
+// admitted.
+type PodAdmissionSettings struct {
+	// What happens to the pods created with a policy that isn't
+	// installed yet, or couldn't be installed. Can be deny, warn or
+	// allow. Defaults to deny.
+	// +kubebuilder:validation:Enum=deny;warn;allow
+	UninstalledPolicy UninstalledPolicyAction `json:"uninstalledPolicy,omitempty"`
+	// How the pods wait for their policy to be installed on their node.
+	// Can be nodeAffinity or readinessGate. Defaults to nodeAffinity.
+	// Only applies to the pods created after it's changed.
+	// +kubebuilder:validation:Enum=nodeAffinity;readinessGate
+	Availability PolicyAvailabilityMode `json:"availability,omitempty"`
+	// Whether pods can only run with the policies that the user creating
+	// them, or their service account, is allowed to "use" by RBAC, on the
+	// selinuxpolicies resource of the policy's name.
+	AuthorizeUse bool `json:"authorizeUse,omitempty"`
+}
+
+// WebhookCertificateSource defines where the webhook server's certificate
+// comes from.
+type WebhookCertificateSource string
+
+const (
+	// The operator issues the certificate from its own CA
+	WebhookCertificatesOperator WebhookCertificateSource = "operator"
+	// The certificate is provided in the certificate directory
+	WebhookCertificatesExternal WebhookCertificateSource = "external"
+)
+
+// SigningSettings defines which policies the operator trusts.
+type SigningSettings struct {
+	// Whether policies need to be signed by one of the trusted keys to
+	// be installed. Policies that are already installed aren't affected
+	// until they change.
+	Required bool `json:"required,omitempty"`
+	// The PEM-encoded ed25519 public keys that policies can be signed
+	// with.
+	TrustedKeys []string `json:"trustedKeys,omitempty"`
+}
+
+// ApprovalSettings defines who needs to approve policies before they're
+// installed.
+type ApprovalSettings struct {
+	// Whether policies need to be approved with a SelinuxPolicyApproval
+	// before they're installed. Policies that are already installed
+	// aren't affected until they change.
+	Required bool `json:"required,omitempty"`
+	// The groups whose members can approve policies.
+	ApproverGroups []string `json:"approverGroups,omitempty"`
+}
+
+// LintSeverity defines how the findings of a lint rule are treated.
+type LintSeverity string
+
+const (
+	// The findings aren't reported
+	LintSeverityIgnore LintSeverity = "ignore"
+	// The findings are reported, but the policy is still installed
+	LintSeverityWarning LintSeverity = "warning"
+	// The findings are reported, and the policy is rejected
+	LintSeverityError LintSeverity = "error"
+)
+
+// LintSettings defines how the policies are linted.
+type LintSettings struct {
+	// Overrides the severity of the lint rules, by rule name. The rules
+	// are: broad-attribute, exec-memory, sensitive-write,
+	// dangerous-capability, container-runtime and unconfined-template.
+	Severities map[string]LintSeverity `json:"severities,omitempty"`
+	// Findings that are accepted, and not reported.
+	Exceptions []LintException `json:"exceptions,omitempty"`
+}
+
+// LintException accepts the findings of a lint rule. Every field that's
+// set needs to match for a finding to be accepted.
+type LintException struct {
+	// The name of the rule
+	Rule string `json:"rule"`
+	// The namespace of the policies. Defaults to all namespaces.
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the policies. Defaults to all policies.
+	Policy string `json:"policy,omitempty"`
+	// The type, attribute, permission or template that the finding is
+	// about. Defaults to any.
+	Subject string `json:"subject,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxOperatorConfig is the Schema for the selinuxoperatorconfigs API.
+// Only the object named "cluster" is taken into account.
+// +kubebuilder:resource:path=selinuxoperatorconfigs,scope=Cluster
+type SelinuxOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SelinuxOperatorConfigSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxOperatorConfigList contains a list of SelinuxOperatorConfig
+type SelinuxOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxOperatorConfig{}, &SelinuxOperatorConfigList{})
+}
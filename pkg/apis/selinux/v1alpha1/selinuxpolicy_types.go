@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -8,6 +9,56 @@ import (
 type SelinuxPolicySpec struct {
 	Apply  bool   `json:"apply,omitempty"`
 	Policy string `json:"policy,omitempty"`
+	// References a precompiled policy package (.pp) to install instead
+	// of the CIL policy. The module contained in the package must be
+	// named <name>_<namespace>.
+	BinaryPolicy *BinaryPolicySource `json:"binaryPolicy,omitempty"`
+	// A pod template to generate a udica-style policy for. If the policy
+	// is empty, the generated policy is written to it, so it can be
+	// reviewed and refined like any other policy.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	GenerateFrom *corev1.PodTemplateSpec `json:"generateFrom,omitempty"`
+	// The version of the udica base templates that the policy was
+	// written against. Defaults to the version installed in the
+	// cluster.
+	TemplateVersion string `json:"templateVersion,omitempty"`
+	// The base64-encoded ed25519 signature of the policy: of its
+	// namespace, name, mode, permissiveUntil and templateVersion, along
+	// with the checksum of the policy field, or of the precompiled
+	// package that binaryPolicy references. Required when the operator
+	// only installs signed policies.
+	Signature string `json:"signature,omitempty"`
+	// Whether the denials of the policy's process type are enforced, or
+	// only logged. Defaults to enforcing.
+	// +kubebuilder:validation:Enum=enforcing;permissive
+	Mode PolicyMode `json:"mode,omitempty"`
+	// If the policy is permissive, the time after which it's installed
+	// in enforcing mode again. The policy itself is left as is, so its
+	// approval and signature still apply.
+	PermissiveUntil *metav1.Time `json:"permissiveUntil,omitempty"`
+}
+
+// PolicyMode defines whether the denials of a policy are enforced.
+type PolicyMode string
+
+const (
+	// The denials of the policy are enforced
+	PolicyModeEnforcing PolicyMode = "enforcing"
+	// The denials of the policy are only logged
+	PolicyModePermissive PolicyMode = "permissive"
+)
+
+// BinaryPolicySource references a policy package stored in the same
+// namespace as the SelinuxPolicy, either in a Secret or in the
+// binaryData of a ConfigMap. Exactly one of the references must be set.
+type BinaryPolicySource struct {
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// The hex-encoded SHA-256 digest of the policy package. The package
+	// is only installed if it matches, so replacing it means changing
+	// the policy, which then needs to be approved again.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{64}$`
+	SHA256 string `json:"sha256"`
 }
 
 // PolicyState defines the state that the policy is in.
@@ -32,6 +83,31 @@ type SelinuxPolicyStatus struct {
 	// Represents the state that the policy is in. Can be:
 	// PENDING, IN-PROGRESS, INSTALLED or ERROR
 	State PolicyState `json:"state,omitempty"`
+	// Human readable details about the state of the policy, such as
+	// the reason it couldn't be installed.
+	Message string `json:"message,omitempty"`
+	// The SHA-256 checksum of the module that was built for the policy.
+	// Every node installs exactly this module.
+	Checksum string `json:"checksum,omitempty"`
+	// The SHA-256 checksum of the policy's current content, along with
+	// its mode, permissiveUntil and templateVersion, which
+	// SelinuxPolicyApprovals refer to.
+	ContentHash string `json:"contentHash,omitempty"`
+	// The mode that the policy was installed in.
+	Mode PolicyMode `json:"mode,omitempty"`
+	// The version of the udica base templates that the policy was
+	// installed with.
+	TemplateVersion string `json:"templateVersion,omitempty"`
+	// The problems found when checking the policy before installing it,
+	// such as unresolved names or invalid permissions.
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+	// Overly broad or dangerous rules found in the policy. They don't
+	// keep the policy from being installed.
+	Warnings []string `json:"warnings,omitempty"`
+	// Allow rules that would permit the accesses that were denied to
+	// the pods using the policy. They're only suggestions: they're
+	// never applied unless they're added to the policy.
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -41,6 +117,7 @@ type SelinuxPolicyStatus struct {
 // +kubebuilder:resource:path=selinuxpolicies,scope=Namespaced
 // +kubebuilder:printcolumn:name="Usage",type="string",JSONPath=`.status.usage`
 // +kubebuilder:printcolumn:name="Apply",type="boolean",JSONPath=`.spec.apply`
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=`.status.mode`
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=`.status.state`
 type SelinuxPolicy struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,45 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxPolicyApprovalSpec defines the policy content that is approved
+type SelinuxPolicyApprovalSpec struct {
+	// The name of the SelinuxPolicy, in the same namespace, that is
+	// approved.
+	PolicyName string `json:"policyName"`
+	// The SHA-256 checksum of the approved content, as shown in the
+	// policy's status. Changing the policy invalidates the approval.
+	ContentHash string `json:"contentHash"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyApproval is the Schema for the selinuxpolicyapprovals API.
+// When the operator requires approvals, a policy is only installed once
+// its content was approved by a member of an approver group other than
+// the user that last changed it. The approver is recorded in the
+// selinux.openshift.io/approver annotation when the approval is created.
+// +kubebuilder:resource:path=selinuxpolicyapprovals,scope=Namespaced
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.spec.policyName`
+// +kubebuilder:printcolumn:name="Approver",type="string",JSONPath=`.metadata.annotations.selinux\.openshift\.io/approver`
+type SelinuxPolicyApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SelinuxPolicyApprovalSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyApprovalList contains a list of SelinuxPolicyApproval
+type SelinuxPolicyApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxPolicyApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxPolicyApproval{}, &SelinuxPolicyApprovalList{})
+}
@@ -0,0 +1,52 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxPolicyBindingSpec defines the pods that run with a policy
+type SelinuxPolicyBindingSpec struct {
+	// The name of the SelinuxPolicy, in the same namespace, that the
+	// matching containers run with.
+	PolicyName string `json:"policyName"`
+	// Selects the pods by their labels. Defaults to every pod.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Only matches the pods that run as this service account.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Only matches the containers that run one of these images. An image
+	// is matched by its full reference, by its name regardless of the tag
+	// or digest, or by its digest (sha256:...). Defaults to every
+	// container of the matching pods.
+	// +optional
+	Images []string `json:"images,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyBinding is the Schema for the selinuxpolicybindings API.
+// The containers of the pods it matches are set to run with the policy
+// when they're created, overriding the SELinux type in their manifest.
+// Containers that reference a policy with an annotation keep that policy.
+// +kubebuilder:resource:path=selinuxpolicybindings,scope=Namespaced
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.spec.policyName`
+type SelinuxPolicyBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SelinuxPolicyBindingSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyBindingList contains a list of SelinuxPolicyBinding
+type SelinuxPolicyBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxPolicyBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxPolicyBinding{}, &SelinuxPolicyBindingList{})
+}
@@ -0,0 +1,52 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxPolicyConstraintSpec defines what the SelinuxPolicies in the
+// selected namespaces may not allow. Since permissive policies allow
+// everything, the policies the constraint applies to can't be permissive.
+type SelinuxPolicyConstraintSpec struct {
+	// Selects the namespaces whose policies the constraint applies to.
+	// Defaults to every namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Types and attributes that policies may not allow access to. Access
+	// to other attributes is only allowed if the policy snapshot shows
+	// that they don't include these.
+	ForbiddenTypes []string `json:"forbiddenTypes,omitempty"`
+	// Object classes that policies may not allow access to
+	ForbiddenClasses []string `json:"forbiddenClasses,omitempty"`
+	// Permissions that policies may not allow, on any class
+	ForbiddenPermissions []string `json:"forbiddenPermissions,omitempty"`
+	// udica templates that policies may not inherit from
+	ForbiddenTemplates []string `json:"forbiddenTemplates,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyConstraint is the Schema for the selinuxpolicyconstraints
+// API. It lets cluster admins restrict what the policies written in the
+// namespaces may allow. Policies that break a constraint are rejected when
+// they're created, and aren't installed. Precompiled policy packages can't
+// be checked, so they're rejected wherever a constraint applies.
+// +kubebuilder:resource:path=selinuxpolicyconstraints,scope=Cluster
+type SelinuxPolicyConstraint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SelinuxPolicyConstraintSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyConstraintList contains a list of SelinuxPolicyConstraint
+type SelinuxPolicyConstraintList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxPolicyConstraint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxPolicyConstraint{}, &SelinuxPolicyConstraintList{})
+}
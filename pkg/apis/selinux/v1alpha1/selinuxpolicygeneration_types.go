@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxPolicyGenerationSpec defines the desired state of SelinuxPolicyGeneration
+type SelinuxPolicyGenerationSpec struct {
+	// The workload, in the same namespace, to generate the policy for
+	WorkloadRef WorkloadReference `json:"workloadRef"`
+	// The name of the SelinuxPolicy holding the draft. Defaults to
+	// the name of the SelinuxPolicyGeneration.
+	PolicyName string `json:"policyName,omitempty"`
+}
+
+// WorkloadReference references a workload that runs pods.
+type WorkloadReference struct {
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet;Pod
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// SelinuxPolicyGenerationStatus defines the observed state of SelinuxPolicyGeneration
+type SelinuxPolicyGenerationStatus struct {
+	// The name of the SelinuxPolicy holding the draft
+	Policy string `json:"policy,omitempty"`
+	// Human readable details about the draft, such as the reason it
+	// couldn't be generated.
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyGeneration is the Schema for the selinuxpolicygenerations API.
+// It keeps a draft SelinuxPolicy, which isn't applied, up to date with the
+// pod template of a workload.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=selinuxpolicygenerations,scope=Namespaced
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=`.spec.workloadRef.kind`
+// +kubebuilder:printcolumn:name="Workload",type="string",JSONPath=`.spec.workloadRef.name`
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.status.policy`
+type SelinuxPolicyGeneration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SelinuxPolicyGenerationSpec   `json:"spec,omitempty"`
+	Status SelinuxPolicyGenerationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyGenerationList contains a list of SelinuxPolicyGeneration
+type SelinuxPolicyGenerationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxPolicyGeneration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxPolicyGeneration{}, &SelinuxPolicyGenerationList{})
+}
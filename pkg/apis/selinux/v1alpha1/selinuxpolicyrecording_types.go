@@ -0,0 +1,80 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxPolicyRecordingSpec defines the desired state of SelinuxPolicyRecording
+type SelinuxPolicyRecordingSpec struct {
+	// The name of the SelinuxPolicy that the recorded policy is written
+	// to. Defaults to the name of the SelinuxPolicyRecording.
+	PolicyName string `json:"policyName,omitempty"`
+	// The udica base templates that the recorded policy inherits from.
+	// The container template is always inherited.
+	Templates []string `json:"templates,omitempty"`
+	// Stops the recording and writes the recorded policy
+	Stop bool `json:"stop,omitempty"`
+}
+
+// RecordingState defines the state that the recording is in.
+type RecordingState string
+
+const (
+	// The denials of the workload are being recorded
+	RecordingStateRecording RecordingState = "RECORDING"
+	// The recording was stopped, the last denials are being collected
+	RecordingStateStopping RecordingState = "STOPPING"
+	// The recorded policy was written
+	RecordingStateCompleted RecordingState = "COMPLETED"
+	// The recorded policy couldn't be written
+	RecordingStateError RecordingState = "ERROR"
+)
+
+// SelinuxPolicyRecordingStatus defines the observed state of SelinuxPolicyRecording
+type SelinuxPolicyRecordingStatus struct {
+	// Represents the state that the recording is in. Can be:
+	// RECORDING, STOPPING, COMPLETED or ERROR
+	State RecordingState `json:"state,omitempty"`
+	// The SELinux type that the workload needs to run with while it's
+	// being recorded.
+	Usage string `json:"usage,omitempty"`
+	// The name of the SelinuxPolicy that the recorded policy is written
+	// to
+	Policy string `json:"policy,omitempty"`
+	// When the recording was stopped
+	StopTimestamp *metav1.Time `json:"stopTimestamp,omitempty"`
+	// Human readable details about the state of the recording
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyRecording is the Schema for the selinuxpolicyrecordings API.
+// While recording, the workload runs with a permissive policy and the
+// accesses it's denied are collected. Once stopped, a SelinuxPolicy
+// allowing those accesses is written, but not applied.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=selinuxpolicyrecordings,scope=Namespaced
+// +kubebuilder:printcolumn:name="Usage",type="string",JSONPath=`.status.usage`
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=`.status.policy`
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=`.status.state`
+type SelinuxPolicyRecording struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SelinuxPolicyRecordingSpec   `json:"spec,omitempty"`
+	Status SelinuxPolicyRecordingStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxPolicyRecordingList contains a list of SelinuxPolicyRecording
+type SelinuxPolicyRecordingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxPolicyRecording `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxPolicyRecording{}, &SelinuxPolicyRecordingList{})
+}
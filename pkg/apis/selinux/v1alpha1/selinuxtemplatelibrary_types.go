@@ -0,0 +1,57 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelinuxTemplateLibrarySpec defines the desired state of SelinuxTemplateLibrary
+type SelinuxTemplateLibrarySpec struct {
+	// The version of the udica base templates in this library
+	Version string `json:"version"`
+	// The blocks that policies written against this version can
+	// inherit from.
+	Templates []string `json:"templates,omitempty"`
+}
+
+// SelinuxTemplateLibraryStatus defines the observed state of SelinuxTemplateLibrary
+type SelinuxTemplateLibraryStatus struct {
+	// Whether this is the version of the library that gets installed
+	// on the nodes. Only one version can be active at a time.
+	Active bool `json:"active,omitempty"`
+	// Represents the state that the library is in. Can be:
+	// PENDING, IN-PROGRESS, INSTALLED or ERROR
+	State PolicyState `json:"state,omitempty"`
+	// Human readable details about the state of the library
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxTemplateLibrary is the Schema for the selinuxtemplatelibraries API.
+// The objects are managed by the operator and describe the udica base
+// templates that it ships.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=selinuxtemplatelibraries,scope=Cluster
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=`.spec.version`
+// +kubebuilder:printcolumn:name="Active",type="boolean",JSONPath=`.status.active`
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=`.status.state`
+type SelinuxTemplateLibrary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SelinuxTemplateLibrarySpec   `json:"spec,omitempty"`
+	Status SelinuxTemplateLibraryStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SelinuxTemplateLibraryList contains a list of SelinuxTemplateLibrary
+type SelinuxTemplateLibraryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelinuxTemplateLibrary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelinuxTemplateLibrary{}, &SelinuxTemplateLibraryList{})
+}
@@ -5,11 +5,604 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalSettings) DeepCopyInto(out *ApprovalSettings) {
+	*out = *in
+	if in.ApproverGroups != nil {
+		in, out := &in.ApproverGroups, &out.ApproverGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalSettings.
+func (in *ApprovalSettings) DeepCopy() *ApprovalSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BinaryPolicySource) DeepCopyInto(out *BinaryPolicySource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BinaryPolicySource.
+func (in *BinaryPolicySource) DeepCopy() *BinaryPolicySource {
+	if in == nil {
+		return nil
+	}
+	out := new(BinaryPolicySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LintException) DeepCopyInto(out *LintException) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintException.
+func (in *LintException) DeepCopy() *LintException {
+	if in == nil {
+		return nil
+	}
+	out := new(LintException)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LintSettings) DeepCopyInto(out *LintSettings) {
+	*out = *in
+	if in.Severities != nil {
+		in, out := &in.Severities, &out.Severities
+		*out = make(map[string]LintSeverity, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Exceptions != nil {
+		in, out := &in.Exceptions, &out.Exceptions
+		*out = make([]LintException, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LintSettings.
+func (in *LintSettings) DeepCopy() *LintSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(LintSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAdmissionSettings) DeepCopyInto(out *PodAdmissionSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAdmissionSettings.
+func (in *PodAdmissionSettings) DeepCopy() *PodAdmissionSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAdmissionSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxDenial) DeepCopyInto(out *SelinuxDenial) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxDenial.
+func (in *SelinuxDenial) DeepCopy() *SelinuxDenial {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxDenial)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxDenial) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxDenialList) DeepCopyInto(out *SelinuxDenialList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxDenial, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxDenialList.
+func (in *SelinuxDenialList) DeepCopy() *SelinuxDenialList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxDenialList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxDenialList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxDenialSpec) DeepCopyInto(out *SelinuxDenialSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxDenialSpec.
+func (in *SelinuxDenialSpec) DeepCopy() *SelinuxDenialSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxDenialSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxDenialStatus) DeepCopyInto(out *SelinuxDenialStatus) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.FirstTimestamp.DeepCopyInto(&out.FirstTimestamp)
+	in.LastTimestamp.DeepCopyInto(&out.LastTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxDenialStatus.
+func (in *SelinuxDenialStatus) DeepCopy() *SelinuxDenialStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxDenialStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxOperatorConfig) DeepCopyInto(out *SelinuxOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxOperatorConfig.
+func (in *SelinuxOperatorConfig) DeepCopy() *SelinuxOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxOperatorConfigList) DeepCopyInto(out *SelinuxOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxOperatorConfigList.
+func (in *SelinuxOperatorConfigList) DeepCopy() *SelinuxOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxOperatorConfigSpec) DeepCopyInto(out *SelinuxOperatorConfigSpec) {
+	*out = *in
+	in.InstallerResources.DeepCopyInto(&out.InstallerResources)
+	if in.InstallerTolerations != nil {
+		in, out := &in.InstallerTolerations, &out.InstallerTolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	in.Lint.DeepCopyInto(&out.Lint)
+	in.Approval.DeepCopyInto(&out.Approval)
+	in.Signing.DeepCopyInto(&out.Signing)
+	out.PodAdmission = in.PodAdmission
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxOperatorConfigSpec.
+func (in *SelinuxOperatorConfigSpec) DeepCopy() *SelinuxOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelinuxPolicy) DeepCopyInto(out *SelinuxPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicy.
+func (in *SelinuxPolicy) DeepCopy() *SelinuxPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyApproval) DeepCopyInto(out *SelinuxPolicyApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyApproval.
+func (in *SelinuxPolicyApproval) DeepCopy() *SelinuxPolicyApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyApprovalList) DeepCopyInto(out *SelinuxPolicyApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxPolicyApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyApprovalList.
+func (in *SelinuxPolicyApprovalList) DeepCopy() *SelinuxPolicyApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyApprovalSpec) DeepCopyInto(out *SelinuxPolicyApprovalSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyApprovalSpec.
+func (in *SelinuxPolicyApprovalSpec) DeepCopy() *SelinuxPolicyApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyBinding) DeepCopyInto(out *SelinuxPolicyBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyBinding.
+func (in *SelinuxPolicyBinding) DeepCopy() *SelinuxPolicyBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyBindingList) DeepCopyInto(out *SelinuxPolicyBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxPolicyBinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyBindingList.
+func (in *SelinuxPolicyBindingList) DeepCopy() *SelinuxPolicyBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyBindingSpec) DeepCopyInto(out *SelinuxPolicyBindingSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyBindingSpec.
+func (in *SelinuxPolicyBindingSpec) DeepCopy() *SelinuxPolicyBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyConstraint) DeepCopyInto(out *SelinuxPolicyConstraint) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyConstraint.
+func (in *SelinuxPolicyConstraint) DeepCopy() *SelinuxPolicyConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyConstraint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyConstraintList) DeepCopyInto(out *SelinuxPolicyConstraintList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxPolicyConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyConstraintList.
+func (in *SelinuxPolicyConstraintList) DeepCopy() *SelinuxPolicyConstraintList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyConstraintList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyConstraintList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyConstraintSpec) DeepCopyInto(out *SelinuxPolicyConstraintSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForbiddenTypes != nil {
+		in, out := &in.ForbiddenTypes, &out.ForbiddenTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenClasses != nil {
+		in, out := &in.ForbiddenClasses, &out.ForbiddenClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenPermissions != nil {
+		in, out := &in.ForbiddenPermissions, &out.ForbiddenPermissions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenTemplates != nil {
+		in, out := &in.ForbiddenTemplates, &out.ForbiddenTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyConstraintSpec.
+func (in *SelinuxPolicyConstraintSpec) DeepCopy() *SelinuxPolicyConstraintSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyConstraintSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyGeneration) DeepCopyInto(out *SelinuxPolicyGeneration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -18,24 +611,89 @@ func (in *SelinuxPolicy) DeepCopyInto(out *SelinuxPolicy) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicy.
-func (in *SelinuxPolicy) DeepCopy() *SelinuxPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyGeneration.
+func (in *SelinuxPolicyGeneration) DeepCopy() *SelinuxPolicyGeneration {
 	if in == nil {
 		return nil
 	}
-	out := new(SelinuxPolicy)
+	out := new(SelinuxPolicyGeneration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SelinuxPolicy) DeepCopyObject() runtime.Object {
+func (in *SelinuxPolicyGeneration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyGenerationList) DeepCopyInto(out *SelinuxPolicyGenerationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxPolicyGeneration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyGenerationList.
+func (in *SelinuxPolicyGenerationList) DeepCopy() *SelinuxPolicyGenerationList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyGenerationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyGenerationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyGenerationSpec) DeepCopyInto(out *SelinuxPolicyGenerationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyGenerationSpec.
+func (in *SelinuxPolicyGenerationSpec) DeepCopy() *SelinuxPolicyGenerationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyGenerationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyGenerationStatus) DeepCopyInto(out *SelinuxPolicyGenerationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyGenerationStatus.
+func (in *SelinuxPolicyGenerationStatus) DeepCopy() *SelinuxPolicyGenerationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyGenerationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelinuxPolicyList) DeepCopyInto(out *SelinuxPolicyList) {
 	*out = *in
@@ -69,9 +727,125 @@ func (in *SelinuxPolicyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyRecording) DeepCopyInto(out *SelinuxPolicyRecording) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyRecording.
+func (in *SelinuxPolicyRecording) DeepCopy() *SelinuxPolicyRecording {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyRecording)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyRecording) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyRecordingList) DeepCopyInto(out *SelinuxPolicyRecordingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxPolicyRecording, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyRecordingList.
+func (in *SelinuxPolicyRecordingList) DeepCopy() *SelinuxPolicyRecordingList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyRecordingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxPolicyRecordingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyRecordingSpec) DeepCopyInto(out *SelinuxPolicyRecordingSpec) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyRecordingSpec.
+func (in *SelinuxPolicyRecordingSpec) DeepCopy() *SelinuxPolicyRecordingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyRecordingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxPolicyRecordingStatus) DeepCopyInto(out *SelinuxPolicyRecordingStatus) {
+	*out = *in
+	if in.StopTimestamp != nil {
+		in, out := &in.StopTimestamp, &out.StopTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxPolicyRecordingStatus.
+func (in *SelinuxPolicyRecordingStatus) DeepCopy() *SelinuxPolicyRecordingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxPolicyRecordingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelinuxPolicySpec) DeepCopyInto(out *SelinuxPolicySpec) {
 	*out = *in
+	if in.BinaryPolicy != nil {
+		in, out := &in.BinaryPolicy, &out.BinaryPolicy
+		*out = new(BinaryPolicySource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GenerateFrom != nil {
+		in, out := &in.GenerateFrom, &out.GenerateFrom
+		*out = new(v1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PermissiveUntil != nil {
+		in, out := &in.PermissiveUntil, &out.PermissiveUntil
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -88,6 +862,21 @@ func (in *SelinuxPolicySpec) DeepCopy() *SelinuxPolicySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelinuxPolicyStatus) DeepCopyInto(out *SelinuxPolicyStatus) {
 	*out = *in
+	if in.ValidationErrors != nil {
+		in, out := &in.ValidationErrors, &out.ValidationErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Suggestions != nil {
+		in, out := &in.Suggestions, &out.Suggestions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -100,3 +889,138 @@ func (in *SelinuxPolicyStatus) DeepCopy() *SelinuxPolicyStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxTemplateLibrary) DeepCopyInto(out *SelinuxTemplateLibrary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxTemplateLibrary.
+func (in *SelinuxTemplateLibrary) DeepCopy() *SelinuxTemplateLibrary {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxTemplateLibrary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxTemplateLibrary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxTemplateLibraryList) DeepCopyInto(out *SelinuxTemplateLibraryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelinuxTemplateLibrary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxTemplateLibraryList.
+func (in *SelinuxTemplateLibraryList) DeepCopy() *SelinuxTemplateLibraryList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxTemplateLibraryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelinuxTemplateLibraryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxTemplateLibrarySpec) DeepCopyInto(out *SelinuxTemplateLibrarySpec) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxTemplateLibrarySpec.
+func (in *SelinuxTemplateLibrarySpec) DeepCopy() *SelinuxTemplateLibrarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxTemplateLibrarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelinuxTemplateLibraryStatus) DeepCopyInto(out *SelinuxTemplateLibraryStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelinuxTemplateLibraryStatus.
+func (in *SelinuxTemplateLibraryStatus) DeepCopy() *SelinuxTemplateLibraryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelinuxTemplateLibraryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SigningSettings) DeepCopyInto(out *SigningSettings) {
+	*out = *in
+	if in.TrustedKeys != nil {
+		in, out := &in.TrustedKeys, &out.TrustedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SigningSettings.
+func (in *SigningSettings) DeepCopy() *SigningSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(SigningSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadReference) DeepCopyInto(out *WorkloadReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadReference.
+func (in *WorkloadReference) DeepCopy() *WorkloadReference {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,135 @@
+// Package audit2allow translates AVC denials into the CIL allow rules that
+// would permit the denied accesses, like audit2allow does for the
+// reference policy.
+package audit2allow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/JAORMX/selinux-operator/pkg/avc"
+	"github.com/JAORMX/selinux-operator/pkg/cil"
+)
+
+// processType is the type that the pods using a policy run with, inside
+// the policy's block.
+const processType = "process"
+
+// Rule is an allow rule for the process type of a policy.
+type Rule struct {
+	Target      string
+	Class       string
+	Permissions []string
+}
+
+// String renders the rule the way it's written in a policy.
+func (r Rule) String() string {
+	return fmt.Sprintf("(allow %s %s ( %s ( %s )))", processType, r.Target, r.Class, strings.Join(r.Permissions, " "))
+}
+
+// Translate converts the denials of the process type of the policy with
+// the given block name into allow rules. The permissions are merged into a
+// single rule per target and class. Denials of other types are ignored.
+func Translate(block string, records []*avc.Record) []Rule {
+	source := block + "." + processType
+	perms := map[[2]string]map[string]bool{}
+	for _, rec := range records {
+		if rec.Source.Type != source {
+			continue
+		}
+		key := [2]string{localType(block, rec.Target.Type), rec.Class}
+		if perms[key] == nil {
+			perms[key] = map[string]bool{}
+		}
+		for _, perm := range rec.Permissions {
+			perms[key][perm] = true
+		}
+	}
+
+	rules := make([]Rule, 0, len(perms))
+	for key, set := range perms {
+		rules = append(rules, Rule{Target: key[0], Class: key[1], Permissions: sortedKeys(set)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Target != rules[j].Target {
+			return rules[i].Target < rules[j].Target
+		}
+		return rules[i].Class < rules[j].Class
+	})
+	return rules
+}
+
+// Subtract removes the permissions that the given policy already allows to
+// its process type from the rules. Rules left without permissions are
+// dropped.
+func Subtract(rules []Rule, policy string) ([]Rule, error) {
+	stmts, err := cil.Parse(policy)
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[[2]string]map[string]bool{}
+	collectAllowed(stmts, allowed)
+
+	var result []Rule
+	for _, rule := range rules {
+		var missing []string
+		for _, perm := range rule.Permissions {
+			if !allowed[[2]string{rule.Target, rule.Class}][perm] {
+				missing = append(missing, perm)
+			}
+		}
+		if len(missing) > 0 {
+			result = append(result, Rule{Target: rule.Target, Class: rule.Class, Permissions: missing})
+		}
+	}
+	return result, nil
+}
+
+// collectAllowed collects the permissions of the allow rules of the process
+// type that use an anonymous class permission, as generated policies do.
+func collectAllowed(stmts []*cil.Node, allowed map[[2]string]map[string]bool) {
+	for _, stmt := range stmts {
+		switch stmt.Keyword() {
+		case "optional":
+			if args := stmt.Args(); len(args) > 0 {
+				collectAllowed(args[1:], allowed)
+			}
+		case "allow":
+			args := stmt.Args()
+			if len(args) != 3 || args[0].IsList || args[0].Atom != processType || args[1].IsList {
+				continue
+			}
+			classPerms := args[2]
+			if !classPerms.IsList || len(classPerms.Children) != 2 || classPerms.Children[0].IsList || !classPerms.Children[1].IsList {
+				continue
+			}
+			key := [2]string{args[1].Atom, classPerms.Children[0].Atom}
+			if allowed[key] == nil {
+				allowed[key] = map[string]bool{}
+			}
+			for _, perm := range classPerms.Children[1].Children {
+				allowed[key][perm.Atom] = true
+			}
+		}
+	}
+}
+
+// localType returns the name that the policy uses for the given type.
+// Types declared in the policy's own block are referred to without the
+// block name.
+func localType(block, typeName string) string {
+	if strings.HasPrefix(typeName, block+".") {
+		return strings.TrimPrefix(typeName, block+".")
+	}
+	return typeName
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package audit2allow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/JAORMX/selinux-operator/pkg/avc"
+)
+
+func denial(source, target, class string, perms ...string) *avc.Record {
+	return &avc.Record{
+		Source:      avc.Context{User: "system_u", Role: "system_r", Type: source, Level: "s0"},
+		Target:      avc.Context{User: "system_u", Role: "object_r", Type: target, Level: "s0"},
+		Class:       class,
+		Permissions: perms,
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	records := []*avc.Record{
+		denial("app_default.process", "var_log_t", "file", "write", "open"),
+		denial("app_default.process", "var_log_t", "file", "append", "open"),
+		denial("app_default.process", "var_log_t", "dir", "search"),
+		denial("app_default.process", "app_default.socket", "sock_file", "write"),
+		// Denials of other policies and types are left out
+		denial("other_default.process", "var_log_t", "file", "read"),
+		denial("container_t", "var_log_t", "file", "read"),
+	}
+	want := []Rule{
+		{Target: "socket", Class: "sock_file", Permissions: []string{"write"}},
+		{Target: "var_log_t", Class: "dir", Permissions: []string{"search"}},
+		{Target: "var_log_t", Class: "file", Permissions: []string{"append", "open", "write"}},
+	}
+	if got := Translate("app_default", records); !reflect.DeepEqual(got, want) {
+		t.Errorf("got rules %v, want %v", got, want)
+	}
+	if got := Translate("app_default", nil); len(got) != 0 {
+		t.Errorf("got rules %v without denials", got)
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	rule := Rule{Target: "var_log_t", Class: "file", Permissions: []string{"append", "open"}}
+	if got, want := rule.String(), "(allow process var_log_t ( file ( append open )))"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	rules := []Rule{
+		{Target: "var_log_t", Class: "file", Permissions: []string{"append", "open", "write"}},
+		{Target: "var_log_t", Class: "dir", Permissions: []string{"search"}},
+		{Target: "etc_t", Class: "file", Permissions: []string{"read"}},
+		{Target: "socket", Class: "sock_file", Permissions: []string{"write"}},
+	}
+	tests := []struct {
+		name   string
+		policy string
+		want   []Rule
+	}{
+		{
+			name:   "empty policy",
+			policy: "",
+			want:   rules,
+		},
+		{
+			name: "partly allowed",
+			policy: `(blockinherit container)
+(allow process var_log_t ( file ( open write )))
+(optional logs
+    (allow process var_log_t ( dir ( search getattr )))
+)`,
+			want: []Rule{
+				{Target: "var_log_t", Class: "file", Permissions: []string{"append"}},
+				{Target: "etc_t", Class: "file", Permissions: []string{"read"}},
+				{Target: "socket", Class: "sock_file", Permissions: []string{"write"}},
+			},
+		},
+		{
+			name: "allowed to other types",
+			policy: `(allow other_t etc_t ( file ( read )))
+(allow process etc_t ( dir ( read )))`,
+			want: rules,
+		},
+		{
+			name: "everything allowed",
+			policy: `(allow process var_log_t ( file ( append open write )))
+(allow process var_log_t ( dir ( search )))
+(allow process etc_t ( file ( read )))
+(allow process socket ( sock_file ( write )))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Subtract(rules, tt.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got rules %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtractSyntaxError(t *testing.T) {
+	if _, err := Subtract(nil, "(allow process"); err == nil {
+		t.Error("expected an error for an unbalanced policy")
+	}
+}
@@ -0,0 +1,115 @@
+// Package avc parses the AVC records that the kernel writes to the audit
+// log when SELinux denies an access.
+package avc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	avcRegex   = regexp.MustCompile(`avc:\s+(denied|granted)\s+\{([^}]*)\}\s+for\s+(.*)$`)
+	stampRegex = regexp.MustCompile(`audit\((\d+)\.(\d+):(\d+)\)`)
+)
+
+// Context is an SELinux security context.
+type Context struct {
+	User  string
+	Role  string
+	Type  string
+	Level string
+}
+
+// ParseContext parses a context in the user:role:type[:level] format.
+func ParseContext(s string) (Context, error) {
+	parts := strings.SplitN(s, ":", 4)
+	if len(parts) < 3 {
+		return Context{}, fmt.Errorf("invalid context '%s'", s)
+	}
+	ctx := Context{User: parts[0], Role: parts[1], Type: parts[2]}
+	if len(parts) == 4 {
+		ctx.Level = parts[3]
+	}
+	return ctx, nil
+}
+
+func (c Context) String() string {
+	s := c.User + ":" + c.Role + ":" + c.Type
+	if c.Level != "" {
+		s += ":" + c.Level
+	}
+	return s
+}
+
+// Record is an AVC denial.
+type Record struct {
+	Timestamp time.Time
+	// The serial number of the audit event
+	Serial uint64
+	// The denied permissions
+	Permissions []string
+	PID         int
+	// The name of the command that was denied
+	Comm string
+	// The name, or path, of the object the access was denied on
+	Name   string
+	Source Context
+	Target Context
+	Class  string
+	// Whether the access was allowed anyway, because the domain or the
+	// system is permissive.
+	Permissive bool
+}
+
+// ParseLine parses a line of the audit log. It returns false if the line
+// isn't an AVC denial.
+func ParseLine(line string) (*Record, bool, error) {
+	m := avcRegex.FindStringSubmatch(line)
+	if m == nil || m[1] != "denied" {
+		return nil, false, nil
+	}
+
+	rec := &Record{Permissions: strings.Fields(m[2])}
+	if stamp := stampRegex.FindStringSubmatch(line); stamp != nil {
+		sec, _ := strconv.ParseInt(stamp[1], 10, 64)
+		msec, _ := strconv.ParseInt(stamp[2], 10, 64)
+		rec.Timestamp = time.Unix(sec, msec*int64(time.Millisecond))
+		rec.Serial, _ = strconv.ParseUint(stamp[3], 10, 64)
+	}
+
+	var err error
+	for _, field := range strings.Fields(m[3]) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.Trim(kv[1], `"`)
+		switch kv[0] {
+		case "pid":
+			rec.PID, _ = strconv.Atoi(value)
+		case "comm":
+			rec.Comm = value
+		case "name", "path":
+			rec.Name = value
+		case "scontext":
+			if rec.Source, err = ParseContext(value); err != nil {
+				return nil, false, err
+			}
+		case "tcontext":
+			if rec.Target, err = ParseContext(value); err != nil {
+				return nil, false, err
+			}
+		case "tclass":
+			rec.Class = value
+		case "permissive":
+			rec.Permissive = value == "1"
+		}
+	}
+	if rec.Source.Type == "" || rec.Target.Type == "" || rec.Class == "" {
+		return nil, false, fmt.Errorf("incomplete AVC record: %s", line)
+	}
+	return rec, true, nil
+}
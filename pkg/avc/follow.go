@@ -0,0 +1,94 @@
+package avc
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// pollInterval is how often the file is checked for new lines once all of
+// them were read.
+const pollInterval = time.Second
+
+// Follow sends the lines appended to the file at the given path to lines,
+// until stop is closed. The file is reopened when it's rotated. If
+// fromStart is false, the lines that are already in the file are skipped.
+func Follow(path string, fromStart bool, lines chan<- string, stop <-chan struct{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	if !fromStart {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	reader := bufio.NewReader(f)
+	var partial string
+	for {
+		line, err := reader.ReadString('\n')
+		if err == nil {
+			select {
+			case lines <- strings.TrimSuffix(partial+line, "\n"):
+			case <-stop:
+				return nil
+			}
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		// Keep incomplete lines until the rest is written
+		partial += line
+
+		// The old file was read to the end, so it's safe to switch
+		rotated, err := isRotated(f, path)
+		if err != nil {
+			return err
+		}
+		if rotated {
+			if newFile, err := os.Open(path); err == nil {
+				f.Close()
+				f = newFile
+				reader.Reset(f)
+				partial = ""
+				continue
+			}
+			// The new file might not have been created yet
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// isRotated returns true if the file at the given path isn't the open file
+// anymore, or if the open file was truncated.
+func isRotated(f *os.File, path string) (bool, error) {
+	current, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	latest, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if !os.SameFile(current, latest) {
+		return true, nil
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	return latest.Size() < offset, nil
+}
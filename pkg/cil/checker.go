@@ -0,0 +1,347 @@
+package cil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/JAORMX/selinux-operator/pkg/templates"
+)
+
+// Problem is an issue found in a policy.
+type Problem struct {
+	// The line of the policy where the problem was found
+	Line int
+	Msg  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Msg)
+}
+
+// Keywords of the statements that declare symbols in a block
+var declarationKeywords = map[string]bool{
+	"type":            true,
+	"typeattribute":   true,
+	"classpermission": true,
+	"classmap":        true,
+	"boolean":         true,
+	"tunable":         true,
+	"macro":           true,
+}
+
+// Operators that can show up in type and permission expressions
+var expressionOperators = map[string]bool{
+	"and": true,
+	"or":  true,
+	"not": true,
+	"xor": true,
+	"all": true,
+}
+
+// Checker checks that the symbols used in a policy resolve against the
+// udica templates and a snapshot of the policy loaded on the nodes.
+type Checker struct {
+	// The symbols declared by each template block
+	blocks map[string]map[string]string
+	// Other blocks that are known to exist, e.g. other policies
+	knownBlocks map[string]bool
+	snapshot    *Snapshot
+}
+
+// NewChecker returns a checker for policies written against the given
+// template library. The snapshot may be nil, in which case symbols that
+// aren't declared by the policy or the templates are assumed to exist on
+// the nodes. knownBlocks are the names of other blocks that policies may
+// refer to, such as the modules of other policies.
+func NewChecker(lib *templates.Library, snapshot *Snapshot, knownBlocks []string) (*Checker, error) {
+	c := &Checker{
+		blocks:      map[string]map[string]string{},
+		knownBlocks: map[string]bool{},
+		snapshot:    snapshot,
+	}
+	for _, block := range knownBlocks {
+		c.knownBlocks[block] = true
+	}
+
+	inherits := map[string][]string{}
+	for _, t := range lib.Templates {
+		stmts, err := Parse(t.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template %s: %w", t.Module, err)
+		}
+		for _, stmt := range stmts {
+			if stmt.Keyword() != "block" || len(stmt.Args()) == 0 {
+				continue
+			}
+			name := stmt.Args()[0].Atom
+			decls := map[string]string{}
+			collectDeclarations(stmt.Args()[1:], decls, func(block string) {
+				inherits[name] = append(inherits[name], block)
+			})
+			c.blocks[name] = decls
+		}
+	}
+	// Templates may build on each other
+	for block, parents := range inherits {
+		for _, parent := range parents {
+			for sym, kind := range c.blocks[parent] {
+				c.blocks[block][sym] = kind
+			}
+		}
+	}
+	return c, nil
+}
+
+func collectDeclarations(stmts []*Node, decls map[string]string, inherit func(string)) {
+	for _, stmt := range stmts {
+		kw := stmt.Keyword()
+		args := stmt.Args()
+		switch {
+		case declarationKeywords[kw] && len(args) > 0:
+			decls[args[0].Atom] = kw
+		case kw == "blockinherit" && len(args) > 0:
+			inherit(args[0].Atom)
+		case kw == "optional" && len(args) > 0:
+			collectDeclarations(args[1:], decls, inherit)
+		}
+	}
+}
+
+// scope holds the symbols visible from within the policy's block
+type scope struct {
+	blockName string
+	decls     map[string]string
+	// Blocks declared within the policy
+	localBlocks map[string]bool
+	problems    []Problem
+}
+
+func (s *scope) report(line int, format string, args ...interface{}) {
+	s.problems = append(s.problems, Problem{Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Check checks the body of the block that wraps the policy with the given
+// name, and returns the problems it finds.
+func (c *Checker) Check(blockName, policy string) []Problem {
+	stmts, err := Parse(policy)
+	if err != nil {
+		if synErr, ok := err.(*SyntaxError); ok {
+			return []Problem{{Line: synErr.Line, Msg: synErr.Msg}}
+		}
+		return []Problem{{Msg: err.Error()}}
+	}
+
+	s := &scope{
+		blockName:   blockName,
+		decls:       map[string]string{},
+		localBlocks: map[string]bool{},
+	}
+	c.declare(s, stmts)
+	c.resolve(s, stmts)
+
+	sort.SliceStable(s.problems, func(i, j int) bool {
+		return s.problems[i].Line < s.problems[j].Line
+	})
+	return s.problems
+}
+
+// declare collects the policy's declarations, including the ones
+// inherited from templates, and reports duplicates.
+func (c *Checker) declare(s *scope, stmts []*Node) {
+	for _, stmt := range stmts {
+		kw := stmt.Keyword()
+		args := stmt.Args()
+		switch {
+		case declarationKeywords[kw] && len(args) > 0:
+			name := args[0].Atom
+			if prev, ok := s.decls[name]; ok {
+				s.report(stmt.Line, "duplicate declaration of %s '%s', it's already declared as a %s", kw, name, prev)
+				continue
+			}
+			s.decls[name] = kw
+		case kw == "block" && len(args) > 0:
+			s.localBlocks[args[0].Atom] = true
+		case kw == "blockinherit" && len(args) > 0:
+			block := args[0].Atom
+			if s.localBlocks[block] {
+				continue
+			}
+			decls, ok := c.blocks[block]
+			if !ok {
+				s.report(stmt.Line, "unknown template '%s'", block)
+				continue
+			}
+			for name, declKw := range decls {
+				if prev, ok := s.decls[name]; ok {
+					s.report(stmt.Line, "duplicate declaration of %s '%s' inherited from template '%s', it's already declared as a %s", declKw, name, block, prev)
+					continue
+				}
+				s.decls[name] = declKw
+			}
+		case kw == "optional" && len(args) > 0:
+			c.declare(s, args[1:])
+		}
+	}
+}
+
+// resolve checks that every symbol used by the policy resolves
+func (c *Checker) resolve(s *scope, stmts []*Node) {
+	for _, stmt := range stmts {
+		args := stmt.Args()
+		switch stmt.Keyword() {
+		case "allow", "auditallow", "dontaudit", "neverallow":
+			if len(args) != 3 {
+				s.report(stmt.Line, "%s expects a source, a target and class permissions", stmt.Keyword())
+				continue
+			}
+			c.resolveTypeExpr(s, args[0])
+			if args[1].Atom != "self" {
+				c.resolveTypeExpr(s, args[1])
+			}
+			c.resolveClassPerms(s, args[2])
+		case "typeattributeset":
+			if len(args) != 2 {
+				s.report(stmt.Line, "typeattributeset expects an attribute and a type expression")
+				continue
+			}
+			c.resolveAttribute(s, args[0])
+			c.resolveTypeExpr(s, args[1])
+		case "typepermissive":
+			if len(args) != 1 {
+				s.report(stmt.Line, "typepermissive expects a type")
+				continue
+			}
+			c.resolveTypeExpr(s, args[0])
+		case "roletype":
+			if len(args) != 2 {
+				s.report(stmt.Line, "roletype expects a role and a type")
+				continue
+			}
+			c.resolveTypeExpr(s, args[1])
+		case "typetransition":
+			if len(args) != 4 && len(args) != 5 {
+				s.report(stmt.Line, "typetransition expects a source, a target, a class, an optional object name and a result type")
+				continue
+			}
+			c.resolveTypeExpr(s, args[0])
+			c.resolveTypeExpr(s, args[1])
+			c.resolveClass(s, args[2])
+			c.resolveTypeExpr(s, args[len(args)-1])
+		case "optional":
+			if len(args) > 0 {
+				c.resolve(s, args[1:])
+			}
+		}
+	}
+}
+
+// resolveTypeExpr checks a type, an attribute or an expression of them
+func (c *Checker) resolveTypeExpr(s *scope, n *Node) {
+	if n.IsList {
+		for _, child := range n.Children {
+			c.resolveTypeExpr(s, child)
+		}
+		return
+	}
+	if expressionOperators[n.Atom] {
+		return
+	}
+	if !c.typeExists(s, n.Atom) {
+		s.report(n.Line, "unresolved type or attribute '%s'", n.Atom)
+	}
+}
+
+func (c *Checker) typeExists(s *scope, name string) bool {
+	if kind, ok := s.decls[name]; ok {
+		return kind == "type" || kind == "typeattribute"
+	}
+	if idx := strings.Index(name, "."); idx > 0 {
+		// Qualified name: accept it if the block it lives in exists
+		block := name[:idx]
+		if block == s.blockName || s.localBlocks[block] || c.knownBlocks[block] {
+			return true
+		}
+		if _, ok := c.blocks[block]; ok {
+			return true
+		}
+		return c.snapshot == nil
+	}
+	if c.snapshot == nil {
+		return true
+	}
+	return c.snapshot.Types[name] || c.snapshot.Attributes[name]
+}
+
+func (c *Checker) resolveAttribute(s *scope, n *Node) {
+	if n.IsList {
+		s.report(n.Line, "expected an attribute, got '%s'", n.String())
+		return
+	}
+	if kind, ok := s.decls[n.Atom]; ok {
+		if kind != "typeattribute" {
+			s.report(n.Line, "'%s' is a %s, not a type attribute", n.Atom, kind)
+		}
+		return
+	}
+	if c.snapshot != nil && !c.snapshot.Attributes[n.Atom] {
+		s.report(n.Line, "unresolved type attribute '%s'", n.Atom)
+	}
+}
+
+func (c *Checker) classes() map[string]map[string]bool {
+	if c.snapshot != nil && len(c.snapshot.Classes) > 0 {
+		return c.snapshot.Classes
+	}
+	return DefaultClasses()
+}
+
+func (c *Checker) resolveClass(s *scope, n *Node) {
+	if n.IsList {
+		s.report(n.Line, "expected a class, got '%s'", n.String())
+		return
+	}
+	if _, ok := c.classes()[n.Atom]; !ok {
+		s.report(n.Line, "unknown class '%s'", n.Atom)
+	}
+}
+
+// resolveClassPerms checks class permissions, which are either a named
+// classpermission set or a (class (permissions)) pair.
+func (c *Checker) resolveClassPerms(s *scope, n *Node) {
+	if !n.IsList {
+		if kind, ok := s.decls[n.Atom]; !ok || kind != "classpermission" {
+			s.report(n.Line, "unresolved class permission set '%s'", n.Atom)
+		}
+		return
+	}
+	if len(n.Children) != 2 || n.Children[0].IsList {
+		s.report(n.Line, "malformed class permissions '%s'", n.String())
+		return
+	}
+	class := n.Children[0].Atom
+	if s.decls[class] == "classmap" {
+		return
+	}
+	perms, ok := c.classes()[class]
+	if !ok {
+		s.report(n.Line, "unknown class '%s'", class)
+		return
+	}
+	c.resolvePerms(s, class, perms, n.Children[1])
+}
+
+func (c *Checker) resolvePerms(s *scope, class string, perms map[string]bool, n *Node) {
+	if n.IsList {
+		for _, child := range n.Children {
+			c.resolvePerms(s, class, perms, child)
+		}
+		return
+	}
+	if expressionOperators[n.Atom] {
+		return
+	}
+	if !perms[n.Atom] {
+		s.report(n.Line, "permission '%s' is not valid for class '%s'", n.Atom, class)
+	}
+}
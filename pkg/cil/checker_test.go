@@ -0,0 +1,164 @@
+package cil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/JAORMX/selinux-operator/pkg/templates"
+)
+
+func TestCheck(t *testing.T) {
+	lib, ok := templates.Get("v0.2.1")
+	if !ok {
+		t.Fatal("the v0.2.1 templates aren't shipped")
+	}
+	snapshot := ParseSnapshot(map[string]string{
+		"types":      "var_log_t etc_t container_runtime_t",
+		"attributes": "file_type domain",
+	})
+
+	tests := []struct {
+		name     string
+		policy   string
+		snapshot *Snapshot
+		want     []string
+	}{
+		{
+			name: "valid policy without a snapshot",
+			policy: `(blockinherit container)
+(allow process unknown_t (file (read open)))`,
+		},
+		{
+			name: "valid policy",
+			policy: `(blockinherit container)
+(type data)
+(typeattribute files)
+(typeattributeset files (data))
+(allow process files (file (read)))
+(allow process (and file_type (not etc_t)) (dir (search)))
+(allow process self (file (not (execute))))
+(allow process other_default.process (unix_stream_socket (connectto)))
+(allow process container.socket (sock_file (write)))
+(typetransition process var_log_t file "app.log" data)`,
+			snapshot: snapshot,
+		},
+		{
+			name:   "syntax error",
+			policy: "(type data)\n(allow process self",
+			want:   []string{"line 2: unbalanced parenthesis"},
+		},
+		{
+			name:   "unknown template",
+			policy: `(blockinherit nope)`,
+			want:   []string{"line 1: unknown template 'nope'"},
+		},
+		{
+			name:   "template declared in the policy",
+			policy: "(block tmpl (type t))\n(blockinherit tmpl)",
+		},
+		{
+			name:   "duplicate declaration",
+			policy: "(type data)\n(typeattribute data)",
+			want:   []string{"line 2: duplicate declaration of typeattribute 'data', it's already declared as a type"},
+		},
+		{
+			name:   "declaration clashing with a template",
+			policy: "(type process)\n(blockinherit container)",
+			want:   []string{"line 2: duplicate declaration of type 'process' inherited from template 'container', it's already declared as a type"},
+		},
+		{
+			name: "unresolved types",
+			policy: `(blockinherit container)
+(allow process nope_t (file (read)))
+(optional o
+    (allow process (and file_type (not gone_t)) (file (read)))
+)
+(allow process gone_default.process (file (read)))`,
+			snapshot: snapshot,
+			want: []string{
+				"line 2: unresolved type or attribute 'nope_t'",
+				"line 4: unresolved type or attribute 'gone_t'",
+				"line 6: unresolved type or attribute 'gone_default.process'",
+			},
+		},
+		{
+			name: "malformed rules",
+			policy: `(allow process self)
+(typeattributeset files)
+(typepermissive)
+(roletype system_r)
+(typetransition process var_log_t)`,
+			want: []string{
+				"line 1: allow expects a source, a target and class permissions",
+				"line 2: typeattributeset expects an attribute and a type expression",
+				"line 3: typepermissive expects a type",
+				"line 4: roletype expects a role and a type",
+				"line 5: typetransition expects a source, a target, a class, an optional object name and a result type",
+			},
+		},
+		{
+			name: "invalid class permissions",
+			policy: `(blockinherit container)
+(allow process self (file (fly)))
+(allow process self (nope (read)))
+(allow process self (file read open))
+(allow process self missing)
+(typetransition process var_log_t (file) process)`,
+			want: []string{
+				"line 2: permission 'fly' is not valid for class 'file'",
+				"line 3: unknown class 'nope'",
+				"line 4: malformed class permissions '(file read open)'",
+				"line 5: unresolved class permission set 'missing'",
+				"line 6: expected a class, got '(file)'",
+			},
+		},
+		{
+			name: "named class permissions and classmaps",
+			policy: `(blockinherit container)
+(classpermission readers)
+(classpermissionset readers (file (read)))
+(classmap files (reader))
+(classmapping files reader readers)
+(allow process self readers)
+(allow process self (files (reader)))`,
+		},
+		{
+			name: "invalid attributes",
+			policy: `(type data)
+(typeattributeset data (data))
+(typeattributeset nope (data))
+(typeattributeset (data) (data))`,
+			snapshot: snapshot,
+			want: []string{
+				"line 2: 'data' is a type, not a type attribute",
+				"line 3: unresolved type attribute 'nope'",
+				"line 4: expected an attribute, got '(data)'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker, err := NewChecker(lib, tt.snapshot, []string{"other_default"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, problem := range checker.Check("app_default", tt.policy) {
+				got = append(got, problem.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got problems %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCheckerInvalidTemplate(t *testing.T) {
+	lib := &templates.Library{
+		Version:   "broken",
+		Templates: []templates.Template{{Module: "broken", Block: "broken", Policy: "(block broken"}},
+	}
+	if _, err := NewChecker(lib, nil, nil); err == nil {
+		t.Error("expected an error for a template that can't be parsed")
+	}
+}
@@ -0,0 +1,142 @@
+// Package cil parses SELinux Common Intermediate Language (CIL) policies
+// and checks them for mistakes before they get installed on the nodes.
+package cil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node is a node of the CIL syntax tree. It's either an atom (a symbol,
+// keyword or string) or a parenthesized list of nodes.
+type Node struct {
+	Atom     string
+	Children []*Node
+	// Whether this is a list, which tells an empty list apart from an
+	// empty atom.
+	IsList bool
+	// The line the node starts at, counting from 1
+	Line int
+}
+
+// Keyword returns the first atom of a list, which is the statement's
+// keyword for statements.
+func (n *Node) Keyword() string {
+	if !n.IsList || len(n.Children) == 0 || n.Children[0].IsList {
+		return ""
+	}
+	return n.Children[0].Atom
+}
+
+// Args returns the arguments of a statement.
+func (n *Node) Args() []*Node {
+	if !n.IsList || len(n.Children) == 0 {
+		return nil
+	}
+	return n.Children[1:]
+}
+
+// String renders the node back into CIL.
+func (n *Node) String() string {
+	if !n.IsList {
+		return n.Atom
+	}
+	parts := make([]string, 0, len(n.Children))
+	for _, child := range n.Children {
+		parts = append(parts, child.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// SyntaxError is returned when the CIL source can't be parsed
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse parses CIL source into its top-level statements.
+func Parse(src string) ([]*Node, error) {
+	p := &parser{src: src, line: 1}
+	root := &Node{IsList: true, Line: 1}
+	stack := []*Node{root}
+	for {
+		tok, line, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok {
+		case "":
+			if len(stack) > 1 {
+				return nil, &SyntaxError{Line: stack[len(stack)-1].Line, Msg: "unbalanced parenthesis"}
+			}
+			return root.Children, nil
+		case "(":
+			list := &Node{IsList: true, Line: line}
+			top := stack[len(stack)-1]
+			top.Children = append(top.Children, list)
+			stack = append(stack, list)
+		case ")":
+			if len(stack) == 1 {
+				return nil, &SyntaxError{Line: line, Msg: "unexpected closing parenthesis"}
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			top := stack[len(stack)-1]
+			top.Children = append(top.Children, &Node{Atom: tok, Line: line})
+		}
+	}
+}
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+}
+
+// next returns the next token and the line it's on. An empty token means
+// the end of the source was reached.
+func (p *parser) next() (string, int, error) {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == ';':
+			// Comments run until the end of the line
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '(' || c == ')':
+			p.pos++
+			return string(c), p.line, nil
+		case c == '"':
+			start, line := p.pos, p.line
+			p.pos++
+			for p.pos < len(p.src) && p.src[p.pos] != '"' {
+				if p.src[p.pos] == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+			if p.pos == len(p.src) {
+				return "", line, &SyntaxError{Line: line, Msg: "unterminated string"}
+			}
+			p.pos++
+			return p.src[start:p.pos], line, nil
+		default:
+			start := p.pos
+			for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n();\"", rune(p.src[p.pos])) {
+				p.pos++
+			}
+			return p.src[start:p.pos], p.line, nil
+		}
+	}
+	return "", p.line, nil
+}
@@ -0,0 +1,183 @@
+package cil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// The statements rendered back into CIL
+		want []string
+		// The line each statement starts at
+		wantLines []int
+	}{
+		{
+			name: "empty",
+			src:  "",
+		},
+		{
+			name: "only comments and whitespace",
+			src:  "; a comment\n\t \r\n;another one",
+		},
+		{
+			name:      "statements",
+			src:       "(type process)\n(allow process self (file (read open)))",
+			want:      []string{"(type process)", "(allow process self (file (read open)))"},
+			wantLines: []int{1, 2},
+		},
+		{
+			name:      "extra whitespace",
+			src:       "\r\n  (  allow\tprocess   var_t ( file ( read ) )  )  \r\n",
+			want:      []string{"(allow process var_t (file (read)))"},
+			wantLines: []int{2},
+		},
+		{
+			name:      "comments",
+			src:       "(type process) ; (type ignored)\n; (type ignored)\n(type socket);trailing",
+			want:      []string{"(type process)", "(type socket)"},
+			wantLines: []int{1, 3},
+		},
+		{
+			name:      "atoms next to parentheses",
+			src:       "(a(b)c)d",
+			want:      []string{"(a (b) c)", "d"},
+			wantLines: []int{1, 1},
+		},
+		{
+			name:      "strings with special characters",
+			src:       `(filecon "/var/lib/app(1);x" file ())`,
+			want:      []string{`(filecon "/var/lib/app(1);x" file ())`},
+			wantLines: []int{1},
+		},
+		{
+			name:      "multi-line string",
+			src:       "(a \"b\nc\")\n(d)",
+			want:      []string{"(a \"b\nc\")", "(d)"},
+			wantLines: []int{1, 3},
+		},
+		{
+			name:      "empty lists",
+			src:       "(() ())",
+			want:      []string{"(() ())"},
+			wantLines: []int{1},
+		},
+		{
+			name:      "nested block",
+			src:       "(block b\n    (optional o\n        (allow process self (file (read)))\n    )\n)",
+			want:      []string{"(block b (optional o (allow process self (file (read)))))"},
+			wantLines: []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			var gotLines []int
+			for _, stmt := range stmts {
+				got = append(got, stmt.String())
+				gotLines = append(gotLines, stmt.Line)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got statements %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotLines, tt.wantLines) {
+				t.Errorf("got lines %v, want %v", gotLines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestParseNestedLines(t *testing.T) {
+	stmts, err := Parse("(block b\n  (optional o\n    (allow process\n      self (file (read)))))")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	optional := stmts[0].Args()[1]
+	allow := optional.Args()[1]
+	if optional.Line != 2 || allow.Line != 3 || allow.Args()[1].Line != 4 {
+		t.Errorf("got lines %d, %d and %d, want 2, 3 and 4", optional.Line, allow.Line, allow.Args()[1].Line)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want SyntaxError
+	}{
+		{
+			name: "unclosed list",
+			src:  "(type process)\n(block b\n  (type socket)",
+			want: SyntaxError{Line: 2, Msg: "unbalanced parenthesis"},
+		},
+		{
+			name: "unclosed nested list",
+			src:  "(block b\n  (type socket\n)",
+			want: SyntaxError{Line: 1, Msg: "unbalanced parenthesis"},
+		},
+		{
+			name: "unexpected closing parenthesis",
+			src:  "(type process)\n\n(type socket))",
+			want: SyntaxError{Line: 3, Msg: "unexpected closing parenthesis"},
+		},
+		{
+			name: "closing parenthesis first",
+			src:  ")(",
+			want: SyntaxError{Line: 1, Msg: "unexpected closing parenthesis"},
+		},
+		{
+			name: "unterminated string",
+			src:  "(type process)\n(filecon \"/var\n/lib file ())",
+			want: SyntaxError{Line: 2, Msg: "unterminated string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			synErr, ok := err.(*SyntaxError)
+			if !ok {
+				t.Fatalf("got error %v, want a syntax error", err)
+			}
+			if *synErr != tt.want {
+				t.Errorf("got %+v, want %+v", *synErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode(t *testing.T) {
+	stmts, err := Parse("(allow process self (file (read))) (()) atom")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	allow, nested, atom := stmts[0], stmts[1], stmts[2]
+
+	if got := allow.Keyword(); got != "allow" {
+		t.Errorf("got keyword %q, want allow", got)
+	}
+	if got := len(allow.Args()); got != 3 {
+		t.Errorf("got %d arguments, want 3", got)
+	}
+	// Neither lists starting with a list nor atoms have keywords
+	if got := nested.Keyword(); got != "" {
+		t.Errorf("got keyword %q for a list starting with a list", got)
+	}
+	if got := atom.Keyword(); got != "" {
+		t.Errorf("got keyword %q for an atom", got)
+	}
+	if got := atom.Args(); got != nil {
+		t.Errorf("got arguments %v for an atom", got)
+	}
+	if got := (&Node{IsList: true}).Args(); got != nil {
+		t.Errorf("got arguments %v for an empty list", got)
+	}
+	if got := nested.Children[0].String(); got != "()" {
+		t.Errorf("got %q for an empty list, want ()", got)
+	}
+}
@@ -0,0 +1,136 @@
+package cil
+
+import (
+	"strings"
+)
+
+// Snapshot describes the policy loaded on the nodes: the types, type
+// attributes and object classes that policies can refer to.
+type Snapshot struct {
+	Types      map[string]bool
+	Attributes map[string]bool
+	// The types of each attribute, for the attributes they're known for
+	AttributeTypes map[string]map[string]bool
+	// The permissions of each class, including the ones it inherits
+	// from its common.
+	Classes map[string]map[string]bool
+}
+
+// ParseSnapshot reads a snapshot from the data of the snapshot ConfigMap.
+// The "types" and "attributes" keys hold whitespace-separated names. The
+// "attributeTypes" key holds a line per attribute with the attribute name
+// followed by its types, and the "classes" key holds a line per class with
+// the class name followed by its permissions. Classes default to the ones
+// found in the reference policy if the "classes" key is missing.
+func ParseSnapshot(data map[string]string) *Snapshot {
+	s := &Snapshot{
+		Types:          map[string]bool{},
+		Attributes:     map[string]bool{},
+		AttributeTypes: parseLines(data["attributeTypes"]),
+		Classes:        parseLines(data["classes"]),
+	}
+	for _, t := range strings.Fields(data["types"]) {
+		s.Types[t] = true
+	}
+	for _, a := range strings.Fields(data["attributes"]) {
+		s.Attributes[a] = true
+	}
+	if len(s.Classes) == 0 {
+		s.Classes = DefaultClasses()
+	}
+	return s
+}
+
+// parseLines parses lines made of a name followed by its members.
+func parseLines(data string) map[string]map[string]bool {
+	result := map[string]map[string]bool{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		members := map[string]bool{}
+		for _, member := range fields[1:] {
+			members[member] = true
+		}
+		result[fields[0]] = members
+	}
+	return result
+}
+
+// DefaultClasses returns the object classes of the reference policy,
+// which are used when the classes on the nodes aren't known.
+func DefaultClasses() map[string]map[string]bool {
+	fileCommon := "ioctl read write create getattr setattr lock relabelfrom relabelto append map unlink link rename execute quotaon mounton audit_access open execmod watch watch_mount watch_sb watch_with_perm watch_reads"
+	socketCommon := "ioctl read write create getattr setattr lock relabelfrom relabelto append map bind connect listen accept getopt setopt shutdown recvfrom sendto name_bind"
+	ipcCommon := "create destroy getattr setattr read write associate unix_read unix_write"
+	capCommon := "chown dac_override dac_read_search fowner fsetid kill setgid setuid setpcap linux_immutable net_bind_service net_broadcast net_admin net_raw ipc_lock ipc_owner sys_module sys_rawio sys_chroot sys_ptrace sys_pacct sys_admin sys_boot sys_nice sys_resource sys_time sys_tty_config mknod lease audit_write audit_control setfcap"
+	cap2Common := "mac_override mac_admin syslog wake_alarm block_suspend audit_read perfmon bpf checkpoint_restore"
+	netlinkCommon := socketCommon + " nlmsg_read nlmsg_write"
+
+	classes := map[string]string{
+		"dir":                           fileCommon + " add_name remove_name reparent search rmdir",
+		"file":                          fileCommon + " execute_no_trans entrypoint",
+		"lnk_file":                      fileCommon,
+		"chr_file":                      fileCommon + " execute_no_trans entrypoint",
+		"blk_file":                      fileCommon,
+		"sock_file":                     fileCommon,
+		"fifo_file":                     fileCommon,
+		"anon_inode":                    fileCommon,
+		"socket":                        socketCommon,
+		"tcp_socket":                    socketCommon + " node_bind name_connect",
+		"udp_socket":                    socketCommon + " node_bind",
+		"rawip_socket":                  socketCommon + " node_bind",
+		"icmp_socket":                   socketCommon + " node_bind",
+		"sctp_socket":                   socketCommon + " node_bind name_connect association",
+		"packet_socket":                 socketCommon,
+		"key_socket":                    socketCommon,
+		"unix_stream_socket":            socketCommon + " connectto",
+		"unix_dgram_socket":             socketCommon,
+		"tun_socket":                    socketCommon + " attach_queue",
+		"vsock_socket":                  socketCommon,
+		"netlink_socket":                socketCommon,
+		"netlink_route_socket":          netlinkCommon,
+		"netlink_tcpdiag_socket":        netlinkCommon,
+		"netlink_xfrm_socket":           netlinkCommon,
+		"netlink_audit_socket":          netlinkCommon + " nlmsg_relay nlmsg_readpriv nlmsg_tty_audit",
+		"netlink_kobject_uevent_socket": socketCommon,
+		"netlink_generic_socket":        socketCommon,
+		"netlink_netfilter_socket":      socketCommon,
+		"process":                       "fork transition sigchld sigkill sigstop signull signal ptrace getsched setsched getsession getpgid setpgid getcap setcap share getattr setexec setfscreate noatsecure siginh setrlimit rlimitinh dyntransition setcurrent execmem execstack execheap setkeycreate setsockcreate getrlimit",
+		"process2":                      "nnp_transition nosuid_transition",
+		"capability":                    capCommon,
+		"cap_userns":                    capCommon,
+		"capability2":                   cap2Common,
+		"cap2_userns":                   cap2Common,
+		"filesystem":                    "mount remount unmount getattr relabelfrom relabelto associate quotamod quotaget watch",
+		"fd":                            "use",
+		"key":                           "view read write search link setattr create",
+		"system":                        "ipc_info syslog_read syslog_mod syslog_console module_request module_load halt reboot status start stop enable disable reload",
+		"security":                      "compute_av compute_create compute_member check_context load_policy compute_relabel compute_user setenforce setbool setsecparam setcheckreqprot read_policy validate_trans",
+		"sem":                           ipcCommon,
+		"msgq":                          ipcCommon + " enqueue",
+		"shm":                           ipcCommon + " lock",
+		"ipc":                           ipcCommon,
+		"msg":                           "send receive",
+		"node":                          "recvfrom sendto",
+		"netif":                         "ingress egress",
+		"peer":                          "recv",
+		"packet":                        "send recv relabelto forward_in forward_out",
+		"association":                   "sendto recvfrom setcontext polmatch",
+		"dbus":                          "acquire_svc send_msg",
+		"service":                       "start stop status reload enable disable",
+		"bpf":                           "map_create map_read map_write prog_load prog_run",
+		"perf_event":                    "open cpu kernel tracepoint read write",
+		"lockdown":                      "integrity confidentiality",
+	}
+
+	result := map[string]map[string]bool{}
+	for class, perms := range classes {
+		result[class] = map[string]bool{}
+		for _, perm := range strings.Fields(perms) {
+			result[class][perm] = true
+		}
+	}
+	return result
+}
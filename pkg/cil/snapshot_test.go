@@ -0,0 +1,63 @@
+package cil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSnapshot(t *testing.T) {
+	s := ParseSnapshot(map[string]string{
+		"types":          "var_log_t\netc_t  container_runtime_t\n",
+		"attributes":     " file_type domain ",
+		"attributeTypes": "file_type var_log_t etc_t\ndomain container_runtime_t\n",
+		"classes":        "file read write open\n\n  dir search \nnoperms\n",
+	})
+	wantTypes := map[string]bool{"var_log_t": true, "etc_t": true, "container_runtime_t": true}
+	if !reflect.DeepEqual(s.Types, wantTypes) {
+		t.Errorf("got types %v, want %v", s.Types, wantTypes)
+	}
+	wantAttributes := map[string]bool{"file_type": true, "domain": true}
+	if !reflect.DeepEqual(s.Attributes, wantAttributes) {
+		t.Errorf("got attributes %v, want %v", s.Attributes, wantAttributes)
+	}
+	wantAttributeTypes := map[string]map[string]bool{
+		"file_type": {"var_log_t": true, "etc_t": true},
+		"domain":    {"container_runtime_t": true},
+	}
+	if !reflect.DeepEqual(s.AttributeTypes, wantAttributeTypes) {
+		t.Errorf("got attribute types %v, want %v", s.AttributeTypes, wantAttributeTypes)
+	}
+	wantClasses := map[string]map[string]bool{
+		"file":    {"read": true, "write": true, "open": true},
+		"dir":     {"search": true},
+		"noperms": {},
+	}
+	if !reflect.DeepEqual(s.Classes, wantClasses) {
+		t.Errorf("got classes %v, want %v", s.Classes, wantClasses)
+	}
+}
+
+func TestParseSnapshotDefaultClasses(t *testing.T) {
+	for name, data := range map[string]map[string]string{
+		"missing classes": {"types": "var_log_t"},
+		"empty classes":   {"classes": " \n\n"},
+	} {
+		s := ParseSnapshot(data)
+		if !reflect.DeepEqual(s.Classes, DefaultClasses()) {
+			t.Errorf("%s: got classes that aren't the defaults", name)
+		}
+	}
+
+	classes := DefaultClasses()
+	for class, perm := range map[string]string{
+		// Inherited from the file common
+		"file": "read",
+		// Declared by the class itself
+		"dir":                "search",
+		"unix_stream_socket": "connectto",
+	} {
+		if !classes[class][perm] {
+			t.Errorf("default class '%s' lacks permission '%s'", class, perm)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Package collector publishes the AVC denials of the processes confined by
+// SelinuxPolicies as SelinuxDenial objects in the policies' namespaces.
+// Denials of the same access are aggregated, and the objects are written
+// at a limited rate, so a noisy workload can't flood the API server.
+package collector
+
+import (
+	"context"
+	hash "crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/avc"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+var log = logf.Log.WithName("avc_collector")
+
+const (
+	// PolicyLabel holds the name of the SelinuxPolicy that a denial
+	// was published for.
+	PolicyLabel = "selinux.openshift.io/policy"
+	// NodeLabel holds the name of the node that a denial happened on.
+	NodeLabel = "selinux.openshift.io/node"
+
+	// maxPending is the number of distinct denials that are kept until
+	// they're published. Denials beyond these are dropped.
+	maxPending = 1000
+)
+
+var containerIDRegex = regexp.MustCompile(`[0-9a-f]{64}`)
+
+type podContainer struct {
+	namespace string
+	pod       string
+	container string
+}
+
+// denial holds the denials of an access since they were last published
+type denial struct {
+	key         types.NamespacedName
+	spec        selinuxv1alpha1.SelinuxDenialSpec
+	permissions []string
+	count       int64
+	first       time.Time
+	last        time.Time
+	containerID string
+	comm        string
+	name        string
+	level       string
+	permissive  bool
+}
+
+// Collector aggregates the AVC denials that happen on a node and publishes
+// them.
+type Collector struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	nodeName string
+	procPath string
+	limiter  flowcontrol.RateLimiter
+
+	mu      sync.Mutex
+	pending map[types.NamespacedName]*denial
+
+	// Maps the IDs of the containers on the node to their pods
+	containers map[string]podContainer
+}
+
+// New returns a collector for the given node. The processes of the node are
+// looked up in procPath, and at most qps objects are written per second.
+func New(c client.Client, scheme *runtime.Scheme, nodeName, procPath string, qps float32, burst int) *Collector {
+	return &Collector{
+		client:     c,
+		scheme:     scheme,
+		nodeName:   nodeName,
+		procPath:   procPath,
+		limiter:    flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		pending:    map[types.NamespacedName]*denial{},
+		containers: map[string]podContainer{},
+	}
+}
+
+// GetDenialName gets the name of the object holding the denials of an
+// access for a policy on a node.
+func GetDenialName(policy, node, source, target, class string) string {
+	hasher := hash.New()
+	fmt.Fprintf(hasher, "%s/%s/%s/%s", node, source, target, class)
+	return fmt.Sprintf("%s-%x", policy, hasher.Sum(nil)[:5])
+}
+
+// Run adds the denials found in the given audit log lines, and publishes
+// them every interval until stop is closed.
+func (c *Collector) Run(lines <-chan string, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case line := <-lines:
+			rec, ok, err := avc.ParseLine(line)
+			if err != nil {
+				log.Error(err, "Failed to parse the audit log")
+				continue
+			}
+			if ok {
+				c.Add(rec)
+			}
+		case <-ticker.C:
+			c.Flush()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Add records a denial. Denials of processes that don't run with a
+// SelinuxPolicy are ignored.
+func (c *Collector) Add(rec *avc.Record) {
+	policy, ns, ok := utils.ParsePolicyUsage(rec.Source.Type)
+	if !ok {
+		return
+	}
+	key := types.NamespacedName{
+		Name:      GetDenialName(policy, c.nodeName, rec.Source.Type, rec.Target.Type, rec.Class),
+		Namespace: ns,
+	}
+	// The process might be gone by the time the denial is published
+	containerID := c.getContainerID(rec.PID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.pending[key]
+	if !ok {
+		if len(c.pending) >= maxPending {
+			log.Info("Too many pending denials, dropping", "SelinuxDenial.Name", key.Name)
+			return
+		}
+		d = &denial{
+			key: key,
+			spec: selinuxv1alpha1.SelinuxDenialSpec{
+				Policy:   policy,
+				NodeName: c.nodeName,
+				Source:   rec.Source.Type,
+				Target:   rec.Target.Type,
+				Class:    rec.Class,
+			},
+			first: rec.Timestamp,
+		}
+		c.pending[key] = d
+	}
+	d.permissions = mergePermissions(d.permissions, rec.Permissions)
+	d.count++
+	d.last = rec.Timestamp
+	if containerID != "" {
+		d.containerID = containerID
+	}
+	d.comm = rec.Comm
+	d.name = rec.Name
+	d.level = rec.Source.Level
+	d.permissive = rec.Permissive
+}
+
+// Flush publishes the pending denials, as far as the rate limit allows.
+// The rest is kept for the next flush.
+func (c *Collector) Flush() {
+	c.mu.Lock()
+	pending := make([]*denial, 0, len(c.pending))
+	for _, d := range c.pending {
+		pending = append(pending, d)
+	}
+	c.mu.Unlock()
+
+	for _, d := range pending {
+		if !c.limiter.TryAccept() {
+			log.Info("Rate limited, delaying the remaining denials", "pending", len(pending))
+			return
+		}
+		c.mu.Lock()
+		// More denials might have been added in the meantime
+		snapshot := *d
+		delete(c.pending, d.key)
+		c.mu.Unlock()
+
+		if err := c.publish(&snapshot); err != nil {
+			log.Error(err, "Failed to publish denial", "SelinuxDenial.Namespace", d.key.Namespace, "SelinuxDenial.Name", d.key.Name)
+			c.requeue(&snapshot)
+		}
+	}
+}
+
+// requeue merges a denial that couldn't be published back into the
+// pending ones.
+func (c *Collector) requeue(d *denial) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	newer, ok := c.pending[d.key]
+	if !ok {
+		c.pending[d.key] = d
+		return
+	}
+	newer.permissions = mergePermissions(newer.permissions, d.permissions)
+	newer.count += d.count
+	newer.first = d.first
+	if newer.containerID == "" {
+		newer.containerID = d.containerID
+	}
+}
+
+func (c *Collector) publish(d *denial) error {
+	policy := &selinuxv1alpha1.SelinuxPolicy{}
+	err := c.client.Get(context.TODO(), types.NamespacedName{Name: d.spec.Policy, Namespace: d.key.Namespace}, policy)
+	if errors.IsNotFound(err) {
+		// The policy is gone, so are its denials
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	found := &selinuxv1alpha1.SelinuxDenial{}
+	err = c.client.Get(context.TODO(), d.key, found)
+	if err != nil && errors.IsNotFound(err) {
+		found = &selinuxv1alpha1.SelinuxDenial{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      d.key.Name,
+				Namespace: d.key.Namespace,
+				Labels: map[string]string{
+					PolicyLabel: d.spec.Policy,
+					NodeLabel:   c.nodeName,
+				},
+			},
+			Spec: d.spec,
+		}
+		// The denials are deleted along with the policy
+		if err := controllerutil.SetControllerReference(policy, found, c.scheme); err != nil {
+			return err
+		}
+		if err := c.client.Create(context.TODO(), found); err != nil {
+			return err
+		}
+		found.Status.FirstTimestamp = metav1.NewTime(d.first)
+	} else if err != nil {
+		return err
+	}
+
+	denialCopy := found.DeepCopy()
+	denialCopy.Status.Permissions = mergePermissions(denialCopy.Status.Permissions, d.permissions)
+	denialCopy.Status.Count += d.count
+	denialCopy.Status.LastTimestamp = metav1.NewTime(d.last)
+	denialCopy.Status.Comm = d.comm
+	denialCopy.Status.Name = d.name
+	denialCopy.Status.Level = d.level
+	denialCopy.Status.Permissive = d.permissive
+	if pc, ok := c.findContainer(d); ok {
+		denialCopy.Status.Pod = pc.pod
+		denialCopy.Status.Container = pc.container
+	}
+	return c.client.Status().Update(context.TODO(), denialCopy)
+}
+
+// getContainerID gets the ID of the container that the process with the
+// given PID runs in, from its cgroups.
+func (c *Collector) getContainerID(pid int) string {
+	if pid == 0 {
+		return ""
+	}
+	data, err := ioutil.ReadFile(filepath.Join(c.procPath, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	return containerIDRegex.FindString(string(data))
+}
+
+// findContainer finds the pod and container that a denial happened in. The
+// container is found by its ID if the process could be looked up, or else
+// by the MCS level and type that the pod runs with.
+func (c *Collector) findContainer(d *denial) (podContainer, bool) {
+	if d.containerID != "" {
+		if pc, ok := c.containers[d.containerID]; ok {
+			return pc, true
+		}
+	}
+
+	pods := &corev1.PodList{}
+	err := c.client.List(context.TODO(), pods, client.InNamespace(d.key.Namespace),
+		client.MatchingFields{"spec.nodeName": c.nodeName})
+	if err != nil {
+		log.Error(err, "Failed to list the pods of the node")
+		return podContainer{}, false
+	}
+	// Forget the containers of the namespace's pods that are gone
+	for id, pc := range c.containers {
+		if pc.namespace == d.key.Namespace {
+			delete(c.containers, id)
+		}
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if i := strings.Index(status.ContainerID, "://"); i >= 0 {
+				c.containers[status.ContainerID[i+3:]] = podContainer{
+					namespace: pod.Namespace,
+					pod:       pod.Name,
+					container: status.Name,
+				}
+			}
+		}
+	}
+	if d.containerID != "" {
+		if pc, ok := c.containers[d.containerID]; ok {
+			return pc, true
+		}
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			var opts *corev1.SELinuxOptions
+			if pod.Spec.SecurityContext != nil {
+				opts = pod.Spec.SecurityContext.SELinuxOptions
+			}
+			if container.SecurityContext != nil && container.SecurityContext.SELinuxOptions != nil {
+				opts = container.SecurityContext.SELinuxOptions
+			}
+			if opts != nil && opts.Type == d.spec.Source && opts.Level == d.level {
+				return podContainer{namespace: pod.Namespace, pod: pod.Name, container: container.Name}, true
+			}
+		}
+	}
+	return podContainer{}, false
+}
+
+// mergePermissions returns the sorted union of the given permissions.
+func mergePermissions(a, b []string) []string {
+	merged := append([]string{}, a...)
+	for _, perm := range b {
+		if !utils.SliceContainsString(merged, perm) {
+			merged = append(merged, perm)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,106 @@
+// Package constraint enforces the SelinuxPolicyConstraints that cluster
+// admins set on the policies of the namespaces.
+package constraint
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/cil"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/lint"
+)
+
+// Check returns how the given policy breaks the constraints that apply to
+// its namespace. Each message is prefixed with the name of the constraint.
+func Check(ctx context.Context, c client.Reader, policy *selinuxv1alpha1.SelinuxPolicy) ([]string, error) {
+	constraints := &selinuxv1alpha1.SelinuxPolicyConstraintList{}
+	if err := c.List(ctx, constraints); err != nil {
+		return nil, err
+	}
+	if len(constraints.Items) == 0 {
+		return nil, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: policy.Namespace}, ns); err != nil {
+		return nil, err
+	}
+	snapshot, err := getSnapshot(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []string
+	for i := range constraints.Items {
+		constraint := &constraints.Items[i]
+		applies, err := appliesTo(constraint, ns)
+		if err != nil {
+			return nil, err
+		}
+		if !applies {
+			continue
+		}
+		if isPermissive(policy) {
+			// A permissive process type is allowed every access
+			violations = append(violations, fmt.Sprintf("%s: policies can't be permissive", constraint.Name))
+			continue
+		}
+		if policy.Spec.BinaryPolicy != nil {
+			// Precompiled policies can't be checked, so they can't
+			// be trusted to follow the constraint
+			violations = append(violations, fmt.Sprintf("%s: precompiled policy packages can't be checked", constraint.Name))
+			continue
+		}
+		found, err := lint.CheckConstraint(policy.Spec.Policy, &constraint.Spec, snapshot)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: the policy can't be checked: %s", constraint.Name, err))
+			continue
+		}
+		for _, violation := range found {
+			violations = append(violations, constraint.Name+": "+violation)
+		}
+	}
+	return violations, nil
+}
+
+// getSnapshot gets the snapshot of the policy loaded on the nodes, which
+// tells what the attributes of the policy include. It's nil if there's
+// none.
+func getSnapshot(ctx context.Context, c client.Reader) (*cil.Snapshot, error) {
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: utils.PolicySnapshotConfigMapName, Namespace: utils.GetOperatorNamespace()}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cil.ParseSnapshot(cm.Data), nil
+}
+
+func appliesTo(constraint *selinuxv1alpha1.SelinuxPolicyConstraint, ns *corev1.Namespace) (bool, error) {
+	if constraint.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(constraint.Spec.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector in constraint %s: %w", constraint.Name, err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// isPermissive returns true if the policy is installed in permissive mode,
+// now or later on.
+func isPermissive(policy *selinuxv1alpha1.SelinuxPolicy) bool {
+	return policy.Spec.Mode == selinuxv1alpha1.PolicyModePermissive &&
+		(policy.Spec.PermissiveUntil == nil || policy.Spec.PermissiveUntil.After(time.Now()))
+}
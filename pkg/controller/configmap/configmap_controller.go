@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +22,7 @@ import (
 
 	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
 	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
 )
 
 var log = logf.Log.WithName("controller_configmap")
@@ -88,6 +90,13 @@ func (r *ReconcileConfigMap) Reconcile(request reconcile.Request) (reconcile.Res
 	if err != nil {
 		return reconcile.Result{}, utils.IgnoreNotFound(err)
 	}
+	cfg, err := operatorconfig.Fetch(context.TODO(), r.client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if version, ok := cminstance.Labels["templateLibrary"]; ok {
+		return r.reconcileTemplateLibrary(cminstance, version, cfg)
+	}
 	policyName, ok := cminstance.Labels["appName"]
 	if !ok {
 		return reconcile.Result{}, nil
@@ -115,55 +124,174 @@ func (r *ReconcileConfigMap) Reconcile(request reconcile.Request) (reconcile.Res
 	}
 	reqLogger.Info("Reconciling pods for policy")
 
+	installCmd := getInstallCommand(cminstance, policyName, policyNamespace)
+	removeCmd := getRemoveCommand(policyName, policyNamespace)
+	foundPods, err := r.reconcileInstallerPods(cminstance, reqLogger, func(node *corev1.Node) *corev1.Pod {
+		labels := map[string]string{
+			"appName":      policyName,
+			"appNamespace": policyNamespace,
+		}
+		return newInstallerPod(utils.GetInstallerPodName(policyName, policyNamespace, node), labels,
+			cminstance.Name, installCmd, removeCmd, node, cfg)
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Pods using the policy are only scheduled onto the nodes it's
+	// installed on
+	nodeLabel := utils.GetPolicyNodeLabel(policyName, policyNamespace)
+	if err := utils.SyncNodeLabel(context.TODO(), r.client, nodeLabel, r.getInstalledNodes(foundPods)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	state, msg := r.getInstallationState(foundPods, reqLogger)
+	if state == selinuxv1alpha1.PolicyStateInProgress {
+		return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+	}
+	policyCopy.Status.State = state
+	policyCopy.Status.Message = msg
+	if err := r.client.Status().Update(context.TODO(), policyCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// reconcileTemplateLibrary installs the udica templates held by the given
+// ConfigMap on every node and reports the result in the library's status.
+func (r *ReconcileConfigMap) reconcileTemplateLibrary(cminstance *corev1.ConfigMap, version string, cfg *selinuxv1alpha1.SelinuxOperatorConfigSpec) (reconcile.Result, error) {
+	reqLogger := log.WithValues("SelinuxTemplateLibrary.Name", version)
+
+	library := &selinuxv1alpha1.SelinuxTemplateLibrary{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: version}, library)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+	libraryCopy := library.DeepCopy()
+
+	if libraryCopy.Status.State == "" || libraryCopy.Status.State == selinuxv1alpha1.PolicyStatePending {
+		libraryCopy.Status.State = selinuxv1alpha1.PolicyStateInProgress
+		r.client.Status().Update(context.TODO(), libraryCopy)
+		// Create another copy so we don't modify the cache
+		libraryCopy = library.DeepCopy()
+	}
+	reqLogger.Info("Reconciling pods for template library")
+
+	installCmd := fmt.Sprintf("semodule -X %d -vi /tmp/policy/*.cil;", utils.PolicyModulePriority)
+	foundPods, err := r.reconcileInstallerPods(cminstance, reqLogger, func(node *corev1.Node) *corev1.Pod {
+		labels := map[string]string{
+			"templateLibrary": version,
+		}
+		// Policies depend on the templates, so they're never removed
+		return newInstallerPod(utils.GetTemplatesInstallerPodName(version, node), labels,
+			cminstance.Name, installCmd, "", node, cfg)
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	state, msg := r.getInstallationState(foundPods, reqLogger)
+	if state == selinuxv1alpha1.PolicyStateInProgress {
+		return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+	}
+	libraryCopy.Status.State = state
+	libraryCopy.Status.Message = msg
+	if err := r.client.Status().Update(context.TODO(), libraryCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// reconcileInstallerPods makes sure that there's an installer pod for the
+// given ConfigMap on every node, and returns the pods that already existed.
+// Pods that installed a different version of the module are replaced.
+func (r *ReconcileConfigMap) reconcileInstallerPods(cminstance *corev1.ConfigMap, logger logr.Logger,
+	newPod func(node *corev1.Node) *corev1.Pod) ([]*corev1.Pod, error) {
 	nodesList := &corev1.NodeList{}
 	foundPods := []*corev1.Pod{}
-	err = r.client.List(context.TODO(), nodesList)
-	for _, node := range nodesList.Items {
+	err := r.client.List(context.TODO(), nodesList)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodesList.Items {
 		// Define a new Pod object
-		pod := newPodForPolicy(policyName, policyNamespace, &node)
+		pod := newPod(&nodesList.Items[i])
+		checksum, hasChecksum := cminstance.Annotations[utils.ChecksumAnnotation]
+		if hasChecksum {
+			pod.Annotations = map[string]string{utils.ChecksumAnnotation: checksum}
+		}
 		if err = controllerutil.SetControllerReference(cminstance, pod, r.scheme); err != nil {
 			log.Error(err, "Failed to set pod ownership", "pod", pod)
-			return reconcile.Result{}, err
+			return nil, err
 		}
 
 		// Check if this Pod already exists
 		found := &corev1.Pod{}
 		err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, found)
 		if err != nil && errors.IsNotFound(err) {
-			reqLogger.Info("Creating a new Pod", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
-			if err = r.client.Create(context.TODO(), pod); err != nil {
-				return reconcile.Result{}, utils.IgnoreAlreadyExists(err)
+			logger.Info("Creating a new Pod", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
+			if err = r.client.Create(context.TODO(), pod); err != nil && !errors.IsAlreadyExists(err) {
+				return nil, err
 			}
 		} else if err != nil {
-			return reconcile.Result{}, err
+			return nil, err
+		} else if found.DeletionTimestamp != nil {
+			// The previous module is still being removed. Report the
+			// pod as running until it's replaced.
+			foundPods = append(foundPods, &corev1.Pod{ObjectMeta: found.ObjectMeta})
+			continue
+		} else if hasChecksum && found.Annotations[utils.ChecksumAnnotation] != checksum {
+			// The pod installed an older version of the module. It's
+			// recreated once it's gone.
+			logger.Info("Replacing outdated Pod", "Pod.Namespace", found.Namespace, "Pod.Name", found.Name)
+			if err = r.client.Delete(context.TODO(), found); err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+			// If it's already gone, it's recreated on the next
+			// reconcile. Either way, report it as running.
+			foundPods = append(foundPods, &corev1.Pod{ObjectMeta: found.ObjectMeta})
+			continue
 		}
 
 		// Pod already exists - don't requeue
 		foundPods = append(foundPods, found)
 	}
+	return foundPods, nil
+}
 
-	// Lets check the state of the pods now
-	for _, pod := range foundPods {
+// getInstallationState checks the state of the installer pods. It returns
+// IN-PROGRESS while any of them is still running.
+func (r *ReconcileConfigMap) getInstallationState(pods []*corev1.Pod, logger logr.Logger) (selinuxv1alpha1.PolicyState, string) {
+	for _, pod := range pods {
 		exitCode, found := r.getInstallerContainerExitCode(pod)
 		// Pod is still running - requeue
 		if !found {
-			reqLogger.Info("Pod still running", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
-			return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+			logger.Info("Pod still running", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
+			return selinuxv1alpha1.PolicyStateInProgress, ""
 		}
 		if exitCode != 0 {
-			reqLogger.Info("Pod failed", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name, "exit-code", exitCode)
-			policyCopy.Status.State = selinuxv1alpha1.PolicyStateError
-			if err := r.client.Status().Update(context.TODO(), policyCopy); err != nil {
-				return reconcile.Result{}, err
-			}
-			return reconcile.Result{}, nil
+			logger.Info("Pod failed", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name, "exit-code", exitCode)
+			return selinuxv1alpha1.PolicyStateError,
+				fmt.Sprintf("installation failed on node '%s' with exit code %d", pod.Spec.NodeName, exitCode)
 		}
 	}
-	policyCopy.Status.State = selinuxv1alpha1.PolicyStateInstalled
-	if err := r.client.Status().Update(context.TODO(), policyCopy); err != nil {
-		return reconcile.Result{}, err
+	return selinuxv1alpha1.PolicyStateInstalled, ""
+}
+
+// getInstalledNodes returns the nodes where the installer pods installed
+// the module. Pods that are being replaced are left out, as they remove the
+// module once they're gone.
+func (r *ReconcileConfigMap) getInstalledNodes(pods []*corev1.Pod) map[string]bool {
+	nodes := map[string]bool{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if exitCode, found := r.getInstallerContainerExitCode(pod); found && exitCode == 0 {
+			nodes[pod.Spec.NodeName] = true
+		}
 	}
-	return reconcile.Result{}, nil
+	return nodes
 }
 
 func (r *ReconcileConfigMap) getInstallerContainerExitCode(pod *corev1.Pod) (int32, bool) {
@@ -178,18 +306,35 @@ func (r *ReconcileConfigMap) getInstallerContainerExitCode(pod *corev1.Pod) (int
 	return -1, false
 }
 
-// newPodForPolicy returns a busybox pod with the same name/namespace as the cr
-func newPodForPolicy(name, ns string, node *corev1.Node) *corev1.Pod {
-	//namespace := "selinux-policy-helper-operator"
-	labels := map[string]string{
-		"appName":      name,
-		"appNamespace": ns,
+// getInstallCommand returns the command that installs the policy module
+// found in the given ConfigMap. The udica templates that CIL policies build
+// on are installed separately, through their template library. If the
+// module was built by the operator, the node only installs it if it's
+// byte-identical to the one that was built.
+func getInstallCommand(cm *corev1.ConfigMap, name, ns string) string {
+	moduleFile := "/tmp/policy/" + utils.GetPolicyModuleFile(cm, name, ns)
+	installCmd := fmt.Sprintf("semodule -X %d -vi %s;", utils.PolicyModulePriority, moduleFile)
+	if checksum, ok := cm.Annotations[utils.ChecksumAnnotation]; ok {
+		return fmt.Sprintf("echo '%s  %s' | sha256sum -c - && %s", checksum, moduleFile, installCmd)
 	}
+	return installCmd
+}
+
+// getRemoveCommand returns the command that removes the policy module
+func getRemoveCommand(name, ns string) string {
+	return fmt.Sprintf("semodule -X %d -vr '%s'", utils.PolicyModulePriority, utils.GetPolicyName(name, ns))
+}
+
+// newInstallerPod returns a pod that installs the modules found in the
+// given ConfigMap on the node. If removeCmd is set, the pod keeps running
+// so the modules can be removed once the pod is deleted.
+func newInstallerPod(podName string, labels map[string]string, cmName, installCmd, removeCmd string, node *corev1.Node,
+	cfg *selinuxv1alpha1.SelinuxOperatorConfigSpec) *corev1.Pod {
 	trueVal := true
 	hostVolTypeDir := corev1.HostPathDirectory
-	return &corev1.Pod{
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      utils.GetInstallerPodName(name, ns, node),
+			Name:      podName,
 			Namespace: utils.GetOperatorNamespace(),
 			Labels:    labels,
 		},
@@ -197,16 +342,17 @@ func newPodForPolicy(name, ns string, node *corev1.Node) *corev1.Pod {
 			Containers: []corev1.Container{
 				{
 					Name:    "policy-installer",
-					Image:   "quay.io/jaosorior/udica",
+					Image:   cfg.InstallerImage,
 					Command: []string{"/bin/sh"},
-					Args:    []string{"-c", "semodule -vi /tmp/policy/*.cil /usr/share/udica/templates/*cil;"},
+					Args:    []string{"-c", installCmd},
 					Lifecycle: &corev1.Lifecycle{
 						PreStop: &corev1.Handler{
 							Exec: &corev1.ExecAction{
-								Command: []string{"/bin/sh", "-c", fmt.Sprintf("semodule -vr '%s'", utils.GetPolicyName(name, ns))},
+								Command: []string{"/bin/sh", "-c", removeCmd},
 							},
 						},
 					},
+					Resources: cfg.InstallerResources,
 					SecurityContext: &corev1.SecurityContext{
 						Privileged: &trueVal,
 					},
@@ -232,16 +378,17 @@ func newPodForPolicy(name, ns string, node *corev1.Node) *corev1.Pod {
 				// This container needs to keep running so we can run the uninstall script.
 				{
 					Name:    "policy-uninstaller",
-					Image:   "quay.io/jaosorior/udica",
+					Image:   cfg.InstallerImage,
 					Command: []string{"/bin/sh"},
 					Args:    []string{"-c", "while true; do sleep 30; done;"},
 					Lifecycle: &corev1.Lifecycle{
 						PreStop: &corev1.Handler{
 							Exec: &corev1.ExecAction{
-								Command: []string{"/bin/sh", "-c", fmt.Sprintf("semodule -vr '%s'", utils.GetPolicyName(name, ns))},
+								Command: []string{"/bin/sh", "-c", removeCmd},
 							},
 						},
 					},
+					Resources: cfg.InstallerResources,
 					SecurityContext: &corev1.SecurityContext{
 						Privileged: &trueVal,
 					},
@@ -268,6 +415,8 @@ func newPodForPolicy(name, ns string, node *corev1.Node) *corev1.Pod {
 			ServiceAccountName: "selinux-operator",
 			RestartPolicy:      corev1.RestartPolicyNever,
 			NodeName:           node.Name,
+			PriorityClassName:  cfg.InstallerPriorityClassName,
+			ImagePullSecrets:   cfg.ImagePullSecrets,
 			Volumes: []corev1.Volume{
 				corev1.Volume{
 					Name: "fsselinux",
@@ -301,19 +450,19 @@ func newPodForPolicy(name, ns string, node *corev1.Node) *corev1.Pod {
 					VolumeSource: corev1.VolumeSource{
 						ConfigMap: &corev1.ConfigMapVolumeSource{
 							LocalObjectReference: corev1.LocalObjectReference{
-								Name: utils.GetPolicyConfigMapName(name, ns),
+								Name: cmName,
 							},
 						},
 					},
 				},
 			},
-			Tolerations: []corev1.Toleration{
-				{
-					Key:      "node-role.kubernetes.io/master",
-					Operator: "Exists",
-					Effect:   "NoSchedule",
-				},
-			},
+			Tolerations: cfg.InstallerTolerations,
 		},
 	}
+	if removeCmd == "" {
+		// Only keep the installer
+		pod.Spec.Containers = pod.Spec.Containers[:1]
+		pod.Spec.Containers[0].Lifecycle = nil
+	}
+	return pod
 }
@@ -0,0 +1,196 @@
+package podreadiness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+var log = logf.Log.WithName("controller_podreadiness")
+
+// nodeNameField indexes the pods by the node they run on
+const nodeNameField = "spec.nodeName"
+
+// Add creates a new pod readiness Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcilePodReadiness{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("podreadiness-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(&corev1.Pod{}, nodeNameField, func(obj runtime.Object) []string {
+		pod := obj.(*corev1.Pod)
+		if pod.Spec.NodeName == "" {
+			return nil
+		}
+		return []string{pod.Spec.NodeName}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource Pod. The pods without the
+	// readiness gate are left out, so they never end up in the queue.
+	gated := func(obj runtime.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		return ok && hasReadinessGate(pod)
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return gated(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return gated(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return gated(e.Object) },
+	})
+	if err != nil {
+		return err
+	}
+
+	// The policies installed on a node are tracked in its labels, so the
+	// pods on the node are checked again whenever they change.
+	mapClient := mgr.GetClient()
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			pods := &corev1.PodList{}
+			err := mapClient.List(context.TODO(), pods, client.MatchingFields{nodeNameField: obj.Meta.GetName()})
+			if err != nil {
+				log.Error(err, "Failed to list the pods of the node", "Node.Name", obj.Meta.GetName())
+				return nil
+			}
+			var requests []reconcile.Request
+			for i := range pods.Items {
+				if hasReadinessGate(&pods.Items[i]) {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: pods.Items[i].Name, Namespace: pods.Items[i].Namespace},
+					})
+				}
+			}
+			return requests
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcilePodReadiness implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcilePodReadiness{}
+
+// ReconcilePodReadiness sets the readiness gate of the pods that wait for
+// their policies to be installed on their node.
+type ReconcilePodReadiness struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile sets the condition of the pod's readiness gate once the
+// policies it runs with are installed on its node.
+func (r *ReconcilePodReadiness) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	pod := &corev1.Pod{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, pod)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+	// Pods are only checked once they're scheduled
+	if !hasReadinessGate(pod) || pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+	reqLogger := log.WithValues("Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
+
+	node := &corev1.Node{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+
+	var missing []string
+	for _, name := range utils.GetPodPolicyNames(pod, pod.Namespace) {
+		if _, ok := node.Labels[utils.GetPolicyNodeLabel(name, pod.Namespace)]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	condition := corev1.PodCondition{
+		Type:   utils.PolicyReadinessGate,
+		Status: corev1.ConditionTrue,
+		Reason: "PolicyInstalled",
+	}
+	if len(missing) > 0 {
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "PolicyNotInstalled"
+		condition.Message = fmt.Sprintf("waiting for SelinuxPolicy %s to be installed on node '%s'",
+			strings.Join(missing, ", "), node.Name)
+	}
+
+	podCopy := pod.DeepCopy()
+	if !setCondition(podCopy, condition) {
+		return reconcile.Result{}, nil
+	}
+	reqLogger.Info("Updating the policy readiness gate", "status", condition.Status)
+	if err := r.client.Status().Update(context.TODO(), podCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func hasReadinessGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == utils.PolicyReadinessGate {
+			return true
+		}
+	}
+	return false
+}
+
+// setCondition sets the condition in the pod's status. It returns false if
+// the pod already had it.
+func setCondition(pod *corev1.Pod, condition corev1.PodCondition) bool {
+	condition.LastTransitionTime = metav1.Now()
+	for i := range pod.Status.Conditions {
+		existing := &pod.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Reason == condition.Reason &&
+			existing.Message == condition.Message {
+			return false
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return true
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	return true
+}
@@ -0,0 +1,137 @@
+package selinuxdenial
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/audit2allow"
+	"github.com/JAORMX/selinux-operator/pkg/avc"
+	"github.com/JAORMX/selinux-operator/pkg/collector"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+var log = logf.Log.WithName("controller_selinuxdenial")
+
+// Add creates a new SelinuxDenial Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileSelinuxDenial{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("selinuxdenial-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// The suggestions are kept in the policy, so the requests are for
+	// the policies that were denied an access.
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxDenial{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			policy, ok := obj.Meta.GetLabels()[collector.PolicyLabel]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: policy, Namespace: obj.Meta.GetNamespace()}},
+			}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Changes to the policy might make some of the suggestions obsolete
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxPolicy{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileSelinuxDenial implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileSelinuxDenial{}
+
+// ReconcileSelinuxDenial reconciles the SelinuxDenial objects of a policy
+type ReconcileSelinuxDenial struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile translates the denials published for a SelinuxPolicy into the
+// allow rules that would permit them, and suggests the ones that the policy
+// doesn't have yet in its status. The rules are never added to the policy.
+func (r *ReconcileSelinuxDenial) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling SelinuxDenials")
+
+	policy := &selinuxv1alpha1.SelinuxPolicy{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, policy)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+	if policy.Spec.BinaryPolicy != nil {
+		// Precompiled policies can't be amended
+		return reconcile.Result{}, nil
+	}
+
+	denials := &selinuxv1alpha1.SelinuxDenialList{}
+	err = r.client.List(context.TODO(), denials, client.InNamespace(policy.Namespace),
+		client.MatchingLabels{collector.PolicyLabel: policy.Name})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	records := make([]*avc.Record, 0, len(denials.Items))
+	for _, denial := range denials.Items {
+		records = append(records, &avc.Record{
+			Permissions: denial.Status.Permissions,
+			Source:      avc.Context{Type: denial.Spec.Source},
+			Target:      avc.Context{Type: denial.Spec.Target},
+			Class:       denial.Spec.Class,
+		})
+	}
+	rules := audit2allow.Translate(utils.GetPolicyName(policy.Name, policy.Namespace), records)
+	rules, err = audit2allow.Subtract(rules, policy.Spec.Policy)
+	if err != nil {
+		// The policy doesn't parse, which is reported by its validation
+		reqLogger.Info("Can't compare the suggestions with the policy", "error", err.Error())
+		return reconcile.Result{}, nil
+	}
+
+	var suggestions []string
+	for _, rule := range rules {
+		suggestions = append(suggestions, rule.String())
+	}
+	if reflect.DeepEqual(suggestions, policy.Status.Suggestions) {
+		return reconcile.Result{}, nil
+	}
+	reqLogger.Info("Updating the suggested rules", "rules", len(suggestions))
+	policyCopy := policy.DeepCopy()
+	policyCopy.Status.Suggestions = suggestions
+	if err := r.client.Status().Update(context.TODO(), policyCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,72 @@
+package selinuxoperatorconfig
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+)
+
+var log = logf.Log.WithName("controller_selinuxoperatorconfig")
+
+// Add creates a new SelinuxOperatorConfig Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileSelinuxOperatorConfig{client: mgr.GetClient()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("selinuxoperatorconfig-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource SelinuxOperatorConfig
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxOperatorConfig{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileSelinuxOperatorConfig implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileSelinuxOperatorConfig{}
+
+// ReconcileSelinuxOperatorConfig reconciles a SelinuxOperatorConfig object
+type ReconcileSelinuxOperatorConfig struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+}
+
+// Reconcile makes the settings in the SelinuxOperatorConfig object the
+// current settings of the operator, so they take effect right away.
+func (r *ReconcileSelinuxOperatorConfig) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Name", request.Name)
+	if request.Name != operatorconfig.Name {
+		reqLogger.Info("Ignoring SelinuxOperatorConfig, only the one named '" + operatorconfig.Name + "' is used")
+		return reconcile.Result{}, nil
+	}
+
+	reqLogger.Info("Reconciling SelinuxOperatorConfig")
+	if _, err := operatorconfig.Fetch(context.TODO(), r.client); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
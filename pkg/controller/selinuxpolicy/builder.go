@@ -0,0 +1,243 @@
+package selinuxpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+)
+
+// The lines of the builder's output between which the built module is
+// printed
+const (
+	moduleBegin = "--- BEGIN MODULE ---"
+	moduleEnd   = "--- END MODULE ---"
+)
+
+// The module is installed into a copy of the node's policy store, so the
+// whole policy gets built without touching the node. The module is then
+// extracted from the store, as the CIL that semodule compiled it to, and
+// printed base64-encoded. Everything else goes to stderr.
+const builderScript = `set -e
+mkdir -p /tmp/root/etc /tmp/root/var/lib /tmp/build
+cp -a /host/etc/selinux /tmp/root/etc/
+cp -a /host/var/lib/selinux /tmp/root/var/lib/
+echo '%[1]s  %[2]s' | sha256sum -c - >&2
+semodule -p /tmp/root -n -X %[3]d -i %[2]s >&2
+cd /tmp/build
+semodule -p /tmp/root -X %[3]d --cil -E %[4]s >&2
+echo '` + moduleBegin + `'
+base64 -w 0 %[4]s.cil
+echo
+echo '` + moduleEnd + `'
+`
+
+// buildModule builds the policy module held by the given ConfigMap once, in
+// a builder pod, before it's shipped to the nodes. It returns the built
+// module once the build is done. A non-empty message is returned if the
+// build failed. Failed builds are kept around until the policy changes, so
+// they're not retried over and over.
+func (r *ReconcileSelinuxPolicy) buildModule(sp *selinuxv1alpha1.SelinuxPolicy, cm *corev1.ConfigMap,
+	logger logr.Logger) ([]byte, string, error) {
+	checksum := cm.Annotations[utils.ChecksumAnnotation]
+	buildCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GetPolicyBuildConfigMapName(sp.Name, sp.Namespace),
+			Namespace: cm.Namespace,
+			Labels: map[string]string{
+				"buildName":      sp.Name,
+				"buildNamespace": sp.Namespace,
+			},
+			Annotations: cm.Annotations,
+		},
+		Data:       cm.Data,
+		BinaryData: cm.BinaryData,
+	}
+
+	foundCM := &corev1.ConfigMap{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: buildCM.Name, Namespace: buildCM.Namespace}, foundCM)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new build ConfigMap", "ConfigMap.Namespace", buildCM.Namespace, "ConfigMap.Name", buildCM.Name)
+		return nil, "", utils.IgnoreAlreadyExists(r.client.Create(context.TODO(), buildCM))
+	} else if err != nil {
+		return nil, "", err
+	}
+	if foundCM.Annotations[utils.ChecksumAnnotation] != checksum {
+		// The policy changed since the build started. Start over, so
+		// the builder doesn't see a stale copy of the module.
+		logger.Info("Policy changed, restarting the build")
+		return nil, "", r.deleteBuild(sp)
+	}
+
+	cfg := operatorconfig.Get()
+	pod := newBuilderPod(sp, foundCM, checksum, cfg)
+	if err := controllerutil.SetControllerReference(foundCM, pod, r.scheme); err != nil {
+		return nil, "", err
+	}
+	foundPod := &corev1.Pod{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, foundPod)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new builder Pod", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
+		return nil, "", utils.IgnoreAlreadyExists(r.client.Create(context.TODO(), pod))
+	} else if err != nil {
+		return nil, "", err
+	}
+	if foundPod.Annotations[utils.ChecksumAnnotation] != checksum {
+		// Left over from a previous build
+		return nil, "", utils.IgnoreNotFound(r.client.Delete(context.TODO(), foundPod))
+	}
+
+	for _, containerStatus := range foundPod.Status.ContainerStatuses {
+		if containerStatus.Name != "policy-builder" || containerStatus.State.Terminated == nil {
+			continue
+		}
+		termState := containerStatus.State.Terminated
+		if termState.ExitCode != 0 {
+			logger.Info("Building the module failed", "Pod.Name", foundPod.Name, "exit-code", termState.ExitCode)
+			return nil, fmt.Sprintf("building the module failed with exit code %d: %s",
+				termState.ExitCode, strings.TrimSpace(termState.Message)), nil
+		}
+		logs, err := r.pods.Pods(foundPod.Namespace).GetLogs(foundPod.Name, &corev1.PodLogOptions{
+			Container: "policy-builder",
+		}).DoRaw()
+		if err != nil {
+			return nil, "", err
+		}
+		module, err := parseBuiltModule(logs)
+		if err != nil {
+			logger.Info("Can't read the built module, restarting the build", "reason", err.Error())
+			return nil, "", r.deleteBuild(sp)
+		}
+		logger.Info("Module built successfully", "source-checksum", checksum, "checksum", utils.GetChecksum(module))
+		return module, "", r.deleteBuild(sp)
+	}
+	logger.Info("Builder pod still running", "Pod.Namespace", foundPod.Namespace, "Pod.Name", foundPod.Name)
+	return nil, "", nil
+}
+
+// parseBuiltModule gets the module that the builder printed
+func parseBuiltModule(logs []byte) ([]byte, error) {
+	begin := bytes.Index(logs, []byte(moduleBegin+"\n"))
+	end := bytes.LastIndex(logs, []byte(moduleEnd))
+	if begin < 0 || end < begin {
+		return nil, fmt.Errorf("the builder's output has no module")
+	}
+	encoded := logs[begin+len(moduleBegin)+1 : end]
+	module, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid module in the builder's output: %w", err)
+	}
+	if len(module) == 0 {
+		return nil, fmt.Errorf("the builder's output has an empty module")
+	}
+	return module, nil
+}
+
+// deleteBuild deletes the build ConfigMap of the policy. The builder pod is
+// owned by it, so it's garbage collected along with it.
+func (r *ReconcileSelinuxPolicy) deleteBuild(sp *selinuxv1alpha1.SelinuxPolicy) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GetPolicyBuildConfigMapName(sp.Name, sp.Namespace),
+			Namespace: utils.GetOperatorNamespace(),
+		},
+	}
+	return utils.IgnoreNotFound(r.client.Delete(context.TODO(), cm))
+}
+
+// newBuilderPod returns a pod that builds the module found in the given
+// ConfigMap against a copy of the policy store of the node it runs on.
+func newBuilderPod(sp *selinuxv1alpha1.SelinuxPolicy, cm *corev1.ConfigMap, checksum string,
+	cfg *selinuxv1alpha1.SelinuxOperatorConfigSpec) *corev1.Pod {
+	trueVal := true
+	hostVolTypeDir := corev1.HostPathDirectory
+	moduleFile := "/tmp/policy/" + utils.GetPolicyModuleFile(cm, sp.Name, sp.Namespace)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        utils.GetBuilderPodName(sp.Name, sp.Namespace),
+			Namespace:   cm.Namespace,
+			Labels:      cm.Labels,
+			Annotations: map[string]string{utils.ChecksumAnnotation: checksum},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:    "policy-builder",
+					Image:   cfg.InstallerImage,
+					Command: []string{"/bin/sh"},
+					Args: []string{"-c", fmt.Sprintf(builderScript, checksum, moduleFile, utils.PolicyModulePriority,
+						utils.GetPolicyName(sp.Name, sp.Namespace))},
+					// semodule reports its errors on stderr
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					Resources:                cfg.InstallerResources,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &trueVal,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						corev1.VolumeMount{
+							Name:      "etcselinux",
+							MountPath: "/host/etc/selinux",
+							ReadOnly:  true,
+						},
+						corev1.VolumeMount{
+							Name:      "varlibselinux",
+							MountPath: "/host/var/lib/selinux",
+							ReadOnly:  true,
+						},
+						corev1.VolumeMount{
+							Name:      "policyvolume",
+							MountPath: "/tmp/policy",
+						},
+					},
+				},
+			},
+			ServiceAccountName: "selinux-operator",
+			RestartPolicy:      corev1.RestartPolicyNever,
+			PriorityClassName:  cfg.InstallerPriorityClassName,
+			ImagePullSecrets:   cfg.ImagePullSecrets,
+			Volumes: []corev1.Volume{
+				corev1.Volume{
+					Name: "etcselinux",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: "/etc/selinux",
+							Type: &hostVolTypeDir,
+						},
+					},
+				},
+				corev1.Volume{
+					Name: "varlibselinux",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: "/var/lib/selinux",
+							Type: &hostVolTypeDir,
+						},
+					},
+				},
+				corev1.Volume{
+					Name: "policyvolume",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: cm.Name,
+							},
+						},
+					},
+				},
+			},
+			Tolerations: cfg.InstallerTolerations,
+		},
+	}
+}
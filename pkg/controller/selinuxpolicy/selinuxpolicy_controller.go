@@ -3,8 +3,12 @@ package selinuxpolicy
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -21,7 +26,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/cil"
+	"github.com/JAORMX/selinux-operator/pkg/constraint"
 	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/generator"
+	"github.com/JAORMX/selinux-operator/pkg/lint"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+	"github.com/JAORMX/selinux-operator/pkg/policypackage"
+	"github.com/JAORMX/selinux-operator/pkg/signature"
+	"github.com/JAORMX/selinux-operator/pkg/templates"
 )
 
 var log = logf.Log.WithName("controller_selinuxpolicy")
@@ -29,7 +42,8 @@ var log = logf.Log.WithName("controller_selinuxpolicy")
 // The underscore is not a valid character in a pod, so we can
 // safely use it as a separator.
 const policyWrapper = `(block {{.Name}}_{{.Namespace}}
-    {{.Policy}}
+    {{.Policy}}{{if .Permissive}}
+    (typepermissive process){{end}}
 )`
 
 const selinuxFinalizerName = "selinuxpolicy.finalizers.selinuxpolicy.openshift.io"
@@ -37,14 +51,24 @@ const selinuxFinalizerName = "selinuxpolicy.finalizers.selinuxpolicy.openshift.i
 // Add creates a new SelinuxPolicy Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 	// Create template to wrap policies
 	tmpl, _ := template.New("policyWrapper").Parse(policyWrapper)
-	return &ReconcileSelinuxPolicy{client: mgr.GetClient(), scheme: mgr.GetScheme(), policyTemplate: tmpl}
+	// The controller-runtime client can't read logs
+	pods, err := corev1client.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &ReconcileSelinuxPolicy{client: mgr.GetClient(), reader: mgr.GetAPIReader(), pods: pods, scheme: mgr.GetScheme(),
+		policyTemplate: tmpl}, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -61,6 +85,23 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Approvals might allow a policy to be installed
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxPolicyApproval{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			approval, ok := obj.Object.(*selinuxv1alpha1.SelinuxPolicyApproval)
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{
+				Name:      approval.Spec.PolicyName,
+				Namespace: approval.Namespace,
+			}}}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -71,7 +112,13 @@ var _ reconcile.Reconciler = &ReconcileSelinuxPolicy{}
 type ReconcileSelinuxPolicy struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client         client.Client
+	client client.Client
+	// Reads objects straight from the apiserver. Used for the Secrets
+	// and ConfigMaps holding binary policies, so we don't end up
+	// caching every one of them in the cluster.
+	reader client.Reader
+	// Reads the logs of the builder pods, which hold the built modules
+	pods           corev1client.PodsGetter
 	scheme         *runtime.Scheme
 	policyTemplate *template.Template
 }
@@ -89,6 +136,10 @@ func (r *ReconcileSelinuxPolicy) Reconcile(request reconcile.Request) (reconcile
 		return reconcile.Result{}, utils.IgnoreNotFound(err)
 	}
 
+	if _, err := operatorconfig.Fetch(context.TODO(), r.client); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	policyCopy := instance.DeepCopy()
 	if policyCopy.Status.State == "" {
 		policyCopy.Status.State = selinuxv1alpha1.PolicyStatePending
@@ -97,6 +148,11 @@ func (r *ReconcileSelinuxPolicy) Reconcile(request reconcile.Request) (reconcile
 		}
 	}
 
+	// Generate a starting policy, which is reviewed like any other
+	if instance.Spec.Policy == "" && instance.Spec.BinaryPolicy == nil && instance.Spec.GenerateFrom != nil {
+		return r.generatePolicy(instance, reqLogger)
+	}
+
 	// If "apply" is false, no need to do anything, let the deployer
 	// review it.
 	if !instance.Spec.Apply {
@@ -143,6 +199,16 @@ func (r *ReconcileSelinuxPolicy) addFinalizer(sp *selinuxv1alpha1.SelinuxPolicy,
 	return reconcile.Result{}, nil
 }
 
+func (r *ReconcileSelinuxPolicy) generatePolicy(sp *selinuxv1alpha1.SelinuxPolicy, logger logr.Logger) (reconcile.Result, error) {
+	spcopy := sp.DeepCopy()
+	spcopy.Spec.Policy = generator.Generate(&spcopy.Spec.GenerateFrom.Spec)
+	logger.Info("Generated policy from pod template")
+	if err := r.client.Update(context.Background(), spcopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
 func (r *ReconcileSelinuxPolicy) addUsageStatus(sp *selinuxv1alpha1.SelinuxPolicy, logger logr.Logger) error {
 	spcopy := sp.DeepCopy()
 	spcopy.Status.Usage = utils.GetPolicyUsage(spcopy.Name, spcopy.Namespace)
@@ -152,6 +218,22 @@ func (r *ReconcileSelinuxPolicy) addUsageStatus(sp *selinuxv1alpha1.SelinuxPolic
 	return nil
 }
 
+func (r *ReconcileSelinuxPolicy) setErrorStatus(sp *selinuxv1alpha1.SelinuxPolicy, msg string) error {
+	spcopy := sp.DeepCopy()
+	spcopy.Status.State = selinuxv1alpha1.PolicyStateError
+	spcopy.Status.Message = msg
+	return r.client.Status().Update(context.Background(), spcopy)
+}
+
+func (r *ReconcileSelinuxPolicy) setValidationErrors(sp *selinuxv1alpha1.SelinuxPolicy, problems, warnings []string) error {
+	spcopy := sp.DeepCopy()
+	spcopy.Status.State = selinuxv1alpha1.PolicyStateError
+	spcopy.Status.Message = "the policy failed validation"
+	spcopy.Status.ValidationErrors = problems
+	spcopy.Status.Warnings = warnings
+	return r.client.Status().Update(context.Background(), spcopy)
+}
+
 func (r *ReconcileSelinuxPolicy) removeFinalizer(sp *selinuxv1alpha1.SelinuxPolicy, logger logr.Logger) (reconcile.Result, error) {
 	spcopy := sp.DeepCopy()
 	spcopy.ObjectMeta.Finalizers = utils.RemoveStringFromSlice(spcopy.ObjectMeta.Finalizers, selinuxFinalizerName)
@@ -162,49 +244,414 @@ func (r *ReconcileSelinuxPolicy) removeFinalizer(sp *selinuxv1alpha1.SelinuxPoli
 }
 
 func (r *ReconcileSelinuxPolicy) reconcileConfigMap(instance *selinuxv1alpha1.SelinuxPolicy, logger logr.Logger) (reconcile.Result, error) {
-	// Define a new ConfigMap object
-	cm := r.newConfigMapForPolicy(instance)
+	// The constraints might have changed since the policy was admitted
+	violations, err := constraint.Check(context.TODO(), r.client, instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(violations) > 0 {
+		logger.Info("The policy breaks the cluster's constraints", "violations", len(violations))
+		return reconcile.Result{}, r.setValidationErrors(instance, violations, nil)
+	}
 
-	// Check if this cm already exists
+	var binaryPolicy []byte
+	if instance.Spec.BinaryPolicy != nil {
+		if mode, _ := getMode(instance); mode == selinuxv1alpha1.PolicyModePermissive {
+			return reconcile.Result{}, r.setErrorStatus(instance, "precompiled policy packages can't be made permissive")
+		}
+		data, msg, err := r.getBinaryPolicy(instance)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if msg != "" {
+			logger.Info("Invalid binary policy", "reason", msg)
+			// The Secrets and ConfigMaps holding the packages aren't
+			// watched, so check again later, backing off each time.
+			return reconcile.Result{Requeue: true}, r.setErrorStatus(instance, msg)
+		}
+		binaryPolicy = data
+	} else {
+		library, msg, err := r.getTemplateLibrary(instance)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if msg != "" {
+			logger.Info("Can't use the udica templates", "reason", msg)
+			return reconcile.Result{}, r.setErrorStatus(instance, msg)
+		}
+		if library.Status.State != selinuxv1alpha1.PolicyStateInstalled {
+			logger.Info("Waiting for the udica templates to be installed", "TemplateVersion", library.Spec.Version)
+			return reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}, nil
+		}
+		problems, err := r.checkPolicy(instance, library)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(problems) > 0 {
+			logger.Info("The policy failed validation", "problems", len(problems))
+			return reconcile.Result{}, r.setValidationErrors(instance, problems, nil)
+		}
+		findings, err := lint.Lint(instance.Spec.Policy, instance.Name, instance.Namespace, &operatorconfig.Get().Lint)
+		if err != nil {
+			return reconcile.Result{}, r.setValidationErrors(instance, []string{err.Error()}, nil)
+		}
+		lintErrors, warnings := lint.Split(findings)
+		if len(lintErrors) > 0 {
+			logger.Info("The policy failed linting", "problems", len(lintErrors))
+			return reconcile.Result{}, r.setValidationErrors(instance, lintErrors, warnings)
+		}
+		if instance.Status.TemplateVersion != library.Spec.Version || len(instance.Status.ValidationErrors) > 0 ||
+			!reflect.DeepEqual(instance.Status.Warnings, warnings) {
+			spcopy := instance.DeepCopy()
+			spcopy.Status.TemplateVersion = library.Spec.Version
+			spcopy.Status.ValidationErrors = nil
+			spcopy.Status.Warnings = warnings
+			if err := r.client.Status().Update(context.TODO(), spcopy); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	// The source of the module, which is built before it's shipped to
+	// the nodes
+	src := r.newConfigMapForPolicy(instance, binaryPolicy)
+	sourceChecksum := src.Annotations[utils.ChecksumAnnotation]
+	content := []byte(instance.Spec.Policy)
+	if binaryPolicy != nil {
+		content = binaryPolicy
+	}
+	contentHash := getContentHash(instance, content)
+	mode, expiry := getMode(instance)
+	result := reconcile.Result{}
+	if expiry > 0 {
+		// Switch back to enforcing once the permissive period is over
+		result = reconcile.Result{Requeue: true, RequeueAfter: expiry}
+	}
+
+	// Check if this cm already exists and is up to date
 	foundCM := &corev1.ConfigMap{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, foundCM)
-	if err != nil && errors.IsNotFound(err) {
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: src.Name, Namespace: src.Namespace}, foundCM)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	exists := err == nil
+	if exists && foundCM.Annotations[utils.SourceChecksumAnnotation] == sourceChecksum {
+		return result, nil
+	}
+
+	cfg := operatorconfig.Get()
+	if cfg.Signing.Required {
+		if err := signature.Verify(signature.Payload(instance, content), instance.Spec.Signature, cfg.Signing.TrustedKeys); err != nil {
+			logger.Info("Refusing to install the policy", "reason", err.Error())
+			return reconcile.Result{}, r.setErrorStatus(instance, err.Error())
+		}
+	}
+	if cfg.Approval.Required {
+		approved, err := r.isApproved(instance, contentHash)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if !approved {
+			return reconcile.Result{}, r.setAwaitingApproval(instance, contentHash, logger)
+		}
+	}
+
+	// Build the module once, instead of on every node
+	module, msg, err := r.buildModule(instance, src, logger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if msg != "" {
+		return reconcile.Result{}, r.setErrorStatus(instance, msg)
+	}
+	if module == nil {
+		return reconcile.Result{Requeue: true, RequeueAfter: 5 * time.Second}, nil
+	}
+	// The nodes install exactly the module that was built
+	cm := newModuleConfigMap(src, instance, module)
+	checksum := cm.Annotations[utils.ChecksumAnnotation]
+
+	if !exists {
 		logger.Info("Creating a new ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
 		if err = r.client.Create(context.TODO(), cm); err != nil {
 			return reconcile.Result{}, utils.IgnoreAlreadyExists(err)
 		}
-
-		// CM created successfully - don't requeue
-		return reconcile.Result{}, nil
-	} else if err != nil {
+	} else {
+		// The installer pods notice the new checksum and reinstall
+		// the module.
+		logger.Info("Updating ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+		cmCopy := foundCM.DeepCopy()
+		cmCopy.Data = cm.Data
+		cmCopy.BinaryData = cm.BinaryData
+		cmCopy.Annotations = cm.Annotations
+		if err = r.client.Update(context.TODO(), cmCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	spcopy := instance.DeepCopy()
+	spcopy.Status.State = selinuxv1alpha1.PolicyStatePending
+	spcopy.Status.Message = ""
+	spcopy.Status.Checksum = checksum
+	spcopy.Status.ContentHash = contentHash
+	spcopy.Status.Mode = mode
+	if err := r.client.Status().Update(context.TODO(), spcopy); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	return result, nil
+}
+
+// isApproved checks whether the given content of the policy was approved
+// by someone other than its author.
+func (r *ReconcileSelinuxPolicy) isApproved(sp *selinuxv1alpha1.SelinuxPolicy, contentHash string) (bool, error) {
+	approvals := &selinuxv1alpha1.SelinuxPolicyApprovalList{}
+	if err := r.client.List(context.TODO(), approvals, client.InNamespace(sp.Namespace)); err != nil {
+		return false, err
+	}
+	author := sp.Annotations[utils.AuthorAnnotation]
+	for _, approval := range approvals.Items {
+		approver := approval.Annotations[utils.ApproverAnnotation]
+		if approval.Spec.PolicyName == sp.Name && approval.Spec.ContentHash == contentHash &&
+			approver != "" && approver != author {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *ReconcileSelinuxPolicy) setAwaitingApproval(sp *selinuxv1alpha1.SelinuxPolicy, contentHash string, logger logr.Logger) error {
+	msg := fmt.Sprintf("waiting for a SelinuxPolicyApproval of content hash %s", contentHash)
+	if sp.Status.State == selinuxv1alpha1.PolicyStatePending && sp.Status.Message == msg && sp.Status.ContentHash == contentHash {
+		return nil
+	}
+	logger.Info("Waiting for the policy to be approved", "ContentHash", contentHash)
+	spcopy := sp.DeepCopy()
+	spcopy.Status.State = selinuxv1alpha1.PolicyStatePending
+	spcopy.Status.Message = msg
+	spcopy.Status.ContentHash = contentHash
+	return r.client.Status().Update(context.TODO(), spcopy)
+}
+
+// getContentHash returns the hash that approvals refer to. It covers the
+// same content and settings that signatures do, which change what gets
+// installed on the nodes.
+func getContentHash(sp *selinuxv1alpha1.SelinuxPolicy, content []byte) string {
+	return utils.GetChecksum(signature.Payload(sp, content))
+}
+
+// getMode returns the mode that the policy needs to be installed in. If
+// the policy is permissive for a limited time, it also returns how long
+// until it has to be switched back to enforcing.
+func getMode(sp *selinuxv1alpha1.SelinuxPolicy) (selinuxv1alpha1.PolicyMode, time.Duration) {
+	if sp.Spec.Mode != selinuxv1alpha1.PolicyModePermissive {
+		return selinuxv1alpha1.PolicyModeEnforcing, 0
+	}
+	if sp.Spec.PermissiveUntil == nil {
+		return selinuxv1alpha1.PolicyModePermissive, 0
+	}
+	remaining := sp.Spec.PermissiveUntil.Sub(time.Now())
+	if remaining <= 0 {
+		return selinuxv1alpha1.PolicyModeEnforcing, 0
+	}
+	return selinuxv1alpha1.PolicyModePermissive, remaining
 }
 
 func (r *ReconcileSelinuxPolicy) deleteConfigMap(instance *selinuxv1alpha1.SelinuxPolicy, logger logr.Logger) error {
-	// Define a new ConfigMap object
-	cm := r.newConfigMapForPolicy(instance)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GetPolicyConfigMapName(instance.Name, instance.Namespace),
+			Namespace: utils.GetOperatorNamespace(),
+		},
+	}
 	logger.Info("Deleting ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
-	return utils.IgnoreNotFound(r.client.Delete(context.TODO(), cm))
+	if err := utils.IgnoreNotFound(r.client.Delete(context.TODO(), cm)); err != nil {
+		return err
+	}
+	// The installer pods remove the module along with the ConfigMap
+	nodeLabel := utils.GetPolicyNodeLabel(instance.Name, instance.Namespace)
+	if err := utils.SyncNodeLabel(context.TODO(), r.client, nodeLabel, nil); err != nil {
+		return err
+	}
+	return r.deleteBuild(instance)
+}
+
+// getBinaryPolicy fetches the policy package referenced by the policy and
+// checks that the module it contains has the name we expect. A non-empty
+// message is returned if the package can't be used.
+func (r *ReconcileSelinuxPolicy) getBinaryPolicy(sp *selinuxv1alpha1.SelinuxPolicy) ([]byte, string, error) {
+	src := sp.Spec.BinaryPolicy
+	var data []byte
+	switch {
+	case src.SecretKeyRef != nil && src.ConfigMapKeyRef != nil:
+		return nil, "binaryPolicy must reference either a Secret or a ConfigMap, not both", nil
+	case src.SecretKeyRef != nil:
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Name: src.SecretKeyRef.Name, Namespace: sp.Namespace}
+		if err := r.reader.Get(context.TODO(), key, secret); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, fmt.Sprintf("Secret '%s' not found in namespace '%s'", key.Name, key.Namespace), nil
+			}
+			return nil, "", err
+		}
+		var ok bool
+		if data, ok = secret.Data[src.SecretKeyRef.Key]; !ok {
+			return nil, fmt.Sprintf("Secret '%s' has no key '%s'", key.Name, src.SecretKeyRef.Key), nil
+		}
+	case src.ConfigMapKeyRef != nil:
+		cm := &corev1.ConfigMap{}
+		key := types.NamespacedName{Name: src.ConfigMapKeyRef.Name, Namespace: sp.Namespace}
+		if err := r.reader.Get(context.TODO(), key, cm); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, fmt.Sprintf("ConfigMap '%s' not found in namespace '%s'", key.Name, key.Namespace), nil
+			}
+			return nil, "", err
+		}
+		var ok bool
+		if data, ok = cm.BinaryData[src.ConfigMapKeyRef.Key]; !ok {
+			return nil, fmt.Sprintf("ConfigMap '%s' has no binaryData key '%s'", key.Name, src.ConfigMapKeyRef.Key), nil
+		}
+	default:
+		return nil, "binaryPolicy must reference either a Secret or a ConfigMap", nil
+	}
+
+	moduleName, err := policypackage.ModuleName(data)
+	if err != nil {
+		// The package might have been stored base64-encoded one
+		// more time, e.g. through a Secret's stringData.
+		decoded, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if decodeErr != nil {
+			return nil, fmt.Sprintf("invalid policy package: %s", err), nil
+		}
+		data = decoded
+		if moduleName, err = policypackage.ModuleName(data); err != nil {
+			return nil, fmt.Sprintf("invalid policy package: %s", err), nil
+		}
+	}
+
+	expectedName := utils.GetPolicyName(sp.Name, sp.Namespace)
+	if moduleName != expectedName {
+		return nil, fmt.Sprintf("policy package contains module '%s', but it must be named '%s'", moduleName, expectedName), nil
+	}
+	// The Secret or ConfigMap can be changed without touching the
+	// policy, so only install the package that the policy names.
+	if digest := utils.GetChecksum(data); digest != src.SHA256 {
+		return nil, fmt.Sprintf("policy package has the SHA-256 digest '%s', but binaryPolicy expects '%s'", digest, src.SHA256), nil
+	}
+	return data, "", nil
 }
 
-func (r *ReconcileSelinuxPolicy) newConfigMapForPolicy(cr *selinuxv1alpha1.SelinuxPolicy) *corev1.ConfigMap {
+// getTemplateLibrary gets the library of udica templates that the policy
+// was written against. A non-empty message is returned if that version of
+// the templates isn't installed in the cluster.
+func (r *ReconcileSelinuxPolicy) getTemplateLibrary(sp *selinuxv1alpha1.SelinuxPolicy) (*selinuxv1alpha1.SelinuxTemplateLibrary, string, error) {
+	installedVersion := utils.GetTemplateVersion()
+	version := sp.Spec.TemplateVersion
+	if version == "" {
+		version = installedVersion
+	}
+
+	library := &selinuxv1alpha1.SelinuxTemplateLibrary{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: version}, library)
+	if errors.IsNotFound(err) {
+		if version == installedVersion {
+			// The library hasn't been published yet
+			return nil, "", err
+		}
+		return nil, fmt.Sprintf("unknown version '%s' of the udica templates", version), nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if version != installedVersion {
+		return nil, fmt.Sprintf("version '%s' of the udica templates is not installed in the cluster, version '%s' is", version, installedVersion), nil
+	}
+	return library, "", nil
+}
+
+// checkPolicy resolves the symbols used by the policy against the templates
+// it was written for and, if it's available, the snapshot of the policy
+// loaded on the nodes. It returns the problems that were found.
+func (r *ReconcileSelinuxPolicy) checkPolicy(sp *selinuxv1alpha1.SelinuxPolicy, library *selinuxv1alpha1.SelinuxTemplateLibrary) ([]string, error) {
+	lib, ok := templates.Get(library.Spec.Version)
+	if !ok {
+		return []string{fmt.Sprintf("the operator doesn't ship version '%s' of the udica templates", library.Spec.Version)}, nil
+	}
+
+	var snapshot *cil.Snapshot
+	snapshotCM := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: utils.PolicySnapshotConfigMapName, Namespace: utils.GetOperatorNamespace()}
+	err := r.client.Get(context.TODO(), key, snapshotCM)
+	if err == nil {
+		snapshot = cil.ParseSnapshot(snapshotCM.Data)
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// Policies may refer to the types of other policies
+	policies := &selinuxv1alpha1.SelinuxPolicyList{}
+	if err := r.client.List(context.TODO(), policies); err != nil {
+		return nil, err
+	}
+	knownBlocks := make([]string, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		knownBlocks = append(knownBlocks, utils.GetPolicyName(policy.Name, policy.Namespace))
+	}
+
+	checker, err := cil.NewChecker(lib, snapshot, knownBlocks)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, problem := range checker.Check(utils.GetPolicyName(sp.Name, sp.Namespace), sp.Spec.Policy) {
+		problems = append(problems, problem.String())
+	}
+	return problems, nil
+}
+
+func (r *ReconcileSelinuxPolicy) newConfigMapForPolicy(cr *selinuxv1alpha1.SelinuxPolicy, binaryPolicy []byte) *corev1.ConfigMap {
 	labels := map[string]string{
 		"appName":      cr.Name,
 		"appNamespace": cr.Namespace,
 	}
 
-	return &corev1.ConfigMap{
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      utils.GetPolicyConfigMapName(cr.Name, cr.Namespace),
 			Namespace: utils.GetOperatorNamespace(),
 			Labels:    labels,
 		},
-		Data: map[string]string{
-			utils.GetPolicyName(cr.Name, cr.Namespace) + ".cil": r.wrapPolicy(cr),
+	}
+	if binaryPolicy != nil {
+		cm.BinaryData = map[string][]byte{
+			utils.GetPolicyName(cr.Name, cr.Namespace) + ".pp": binaryPolicy,
+		}
+	} else {
+		wrapped := r.wrapPolicy(cr)
+		cm.Data = map[string]string{
+			utils.GetPolicyName(cr.Name, cr.Namespace) + ".cil": wrapped,
+		}
+		binaryPolicy = []byte(wrapped)
+	}
+	// The builder checks this before building the module
+	cm.Annotations = map[string]string{
+		utils.ChecksumAnnotation: utils.GetChecksum(binaryPolicy),
+	}
+	return cm
+}
+
+// newModuleConfigMap returns the ConfigMap that ships the module built from
+// the given source ConfigMap to the nodes.
+func newModuleConfigMap(src *corev1.ConfigMap, cr *selinuxv1alpha1.SelinuxPolicy, module []byte) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: *src.ObjectMeta.DeepCopy(),
+		BinaryData: map[string][]byte{
+			utils.GetPolicyName(cr.Name, cr.Namespace) + ".cil": module,
 		},
 	}
+	cm.Annotations = map[string]string{
+		utils.ChecksumAnnotation:       utils.GetChecksum(module),
+		utils.SourceChecksumAnnotation: src.Annotations[utils.ChecksumAnnotation],
+	}
+	return cm
 }
 
 func (r *ReconcileSelinuxPolicy) wrapPolicy(cr *selinuxv1alpha1.SelinuxPolicy) string {
@@ -213,14 +660,17 @@ func (r *ReconcileSelinuxPolicy) wrapPolicy(cr *selinuxv1alpha1.SelinuxPolicy) s
 	parsedpolicy = strings.ReplaceAll(parsedpolicy, "\n", "\n    ")
 	// replace empty lines
 	parsedpolicy = strings.TrimSpace(parsedpolicy)
+	mode, _ := getMode(cr)
 	data := struct {
-		Name      string
-		Namespace string
-		Policy    string
+		Name       string
+		Namespace  string
+		Policy     string
+		Permissive bool
 	}{
-		Name:      cr.Name,
-		Namespace: cr.Namespace,
-		Policy:    parsedpolicy,
+		Name:       cr.Name,
+		Namespace:  cr.Namespace,
+		Policy:     parsedpolicy,
+		Permissive: mode == selinuxv1alpha1.PolicyModePermissive,
 	}
 	var result bytes.Buffer
 	r.policyTemplate.Execute(&result, data)
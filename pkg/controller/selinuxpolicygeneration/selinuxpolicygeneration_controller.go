@@ -0,0 +1,245 @@
+package selinuxpolicygeneration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/generator"
+)
+
+var log = logf.Log.WithName("controller_selinuxpolicygeneration")
+
+// Add creates a new SelinuxPolicyGeneration Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileSelinuxPolicyGeneration{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("selinuxpolicygeneration-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource SelinuxPolicyGeneration
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxPolicyGeneration{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the draft policies
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxPolicy{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &selinuxv1alpha1.SelinuxPolicyGeneration{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Regenerate the drafts when the workloads they're for change
+	workloads := map[string]runtime.Object{
+		"Deployment":  &appsv1.Deployment{},
+		"StatefulSet": &appsv1.StatefulSet{},
+		"DaemonSet":   &appsv1.DaemonSet{},
+		"Pod":         &corev1.Pod{},
+	}
+	for kind, obj := range workloads {
+		err = c.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: mapWorkload(mgr.GetClient(), kind),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mapWorkload returns the generation requests that reference a workload of
+// the given kind.
+func mapWorkload(c client.Client, kind string) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		generations := &selinuxv1alpha1.SelinuxPolicyGenerationList{}
+		if err := c.List(context.TODO(), generations, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+			log.Error(err, "Failed to list the policy generation requests")
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, gen := range generations.Items {
+			if gen.Spec.WorkloadRef.Kind == kind && gen.Spec.WorkloadRef.Name == obj.Meta.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: gen.Name, Namespace: gen.Namespace},
+				})
+			}
+		}
+		return requests
+	}
+}
+
+// blank assignment to verify that ReconcileSelinuxPolicyGeneration implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileSelinuxPolicyGeneration{}
+
+// ReconcileSelinuxPolicyGeneration reconciles a SelinuxPolicyGeneration object
+type ReconcileSelinuxPolicyGeneration struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile generates a policy for the workload referenced by the
+// SelinuxPolicyGeneration and keeps the draft SelinuxPolicy up to date with
+// it. Drafts are never applied by the operator; once someone applies one,
+// it's no longer updated.
+func (r *ReconcileSelinuxPolicyGeneration) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling SelinuxPolicyGeneration")
+
+	// Fetch the SelinuxPolicyGeneration instance
+	instance := &selinuxv1alpha1.SelinuxPolicyGeneration{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+
+	policyName := instance.Spec.PolicyName
+	if policyName == "" {
+		policyName = instance.Name
+	}
+
+	spec, msg, err := r.getPodSpec(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if msg == "" {
+		msg, err = r.reconcileDraft(instance, policyName, generator.Generate(spec), reqLogger)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if instance.Status.Policy != policyName || instance.Status.Message != msg {
+		genCopy := instance.DeepCopy()
+		genCopy.Status.Policy = policyName
+		genCopy.Status.Message = msg
+		if err := r.client.Status().Update(context.TODO(), genCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// reconcileDraft creates or updates the draft policy. A non-empty message
+// is returned if the draft can't be updated.
+func (r *ReconcileSelinuxPolicyGeneration) reconcileDraft(gen *selinuxv1alpha1.SelinuxPolicyGeneration, policyName, policy string,
+	logger logr.Logger) (string, error) {
+	draft := &selinuxv1alpha1.SelinuxPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      policyName,
+			Namespace: gen.Namespace,
+		},
+		Spec: selinuxv1alpha1.SelinuxPolicySpec{
+			Apply:  false,
+			Policy: policy,
+		},
+	}
+	if err := controllerutil.SetControllerReference(gen, draft, r.scheme); err != nil {
+		return "", err
+	}
+
+	found := &selinuxv1alpha1.SelinuxPolicy{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: draft.Name, Namespace: draft.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new draft SelinuxPolicy", "SelinuxPolicy.Name", draft.Name)
+		return "", utils.IgnoreAlreadyExists(r.client.Create(context.TODO(), draft))
+	} else if err != nil {
+		return "", err
+	}
+
+	if !metav1.IsControlledBy(found, gen) {
+		if found.Spec.Apply {
+			return fmt.Sprintf("SelinuxPolicy '%s' was applied, so it's no longer updated", found.Name), nil
+		}
+		return fmt.Sprintf("SelinuxPolicy '%s' already exists and isn't a draft of this generation", found.Name), nil
+	}
+
+	policyCopy := found.DeepCopy()
+	if found.Spec.Apply {
+		// The draft was accepted. It now lives on its own, so deleting
+		// the generation request doesn't delete the policy.
+		logger.Info("Draft SelinuxPolicy was applied, releasing it", "SelinuxPolicy.Name", found.Name)
+		var owners []metav1.OwnerReference
+		for _, owner := range found.OwnerReferences {
+			if owner.UID != gen.UID {
+				owners = append(owners, owner)
+			}
+		}
+		policyCopy.OwnerReferences = owners
+		return fmt.Sprintf("SelinuxPolicy '%s' was applied, so it's no longer updated", found.Name),
+			r.client.Update(context.TODO(), policyCopy)
+	}
+	if found.Spec.Policy != policy {
+		logger.Info("Updating draft SelinuxPolicy", "SelinuxPolicy.Name", found.Name)
+		policyCopy.Spec.Policy = policy
+		return "", r.client.Update(context.TODO(), policyCopy)
+	}
+	return "", nil
+}
+
+// getPodSpec gets the pod spec of the referenced workload. A non-empty
+// message is returned if the workload doesn't exist.
+func (r *ReconcileSelinuxPolicyGeneration) getPodSpec(gen *selinuxv1alpha1.SelinuxPolicyGeneration) (*corev1.PodSpec, string, error) {
+	ref := gen.Spec.WorkloadRef
+	key := types.NamespacedName{Name: ref.Name, Namespace: gen.Namespace}
+	var spec *corev1.PodSpec
+	var obj runtime.Object
+	switch ref.Kind {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		obj, spec = deployment, &deployment.Spec.Template.Spec
+	case "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		obj, spec = statefulSet, &statefulSet.Spec.Template.Spec
+	case "DaemonSet":
+		daemonSet := &appsv1.DaemonSet{}
+		obj, spec = daemonSet, &daemonSet.Spec.Template.Spec
+	case "Pod":
+		pod := &corev1.Pod{}
+		obj, spec = pod, &pod.Spec
+	default:
+		return nil, fmt.Sprintf("unsupported workload kind '%s'", ref.Kind), nil
+	}
+
+	if err := r.client.Get(context.TODO(), key, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Sprintf("%s '%s' not found", ref.Kind, ref.Name), nil
+		}
+		return nil, "", err
+	}
+	return spec, "", nil
+}
@@ -0,0 +1,283 @@
+package selinuxpolicyrecording
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/audit2allow"
+	"github.com/JAORMX/selinux-operator/pkg/avc"
+	"github.com/JAORMX/selinux-operator/pkg/collector"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+var log = logf.Log.WithName("controller_selinuxpolicyrecording")
+
+// RecordingLabel holds the name of the SelinuxPolicyRecording that a policy
+// was recorded by.
+const RecordingLabel = "selinux.openshift.io/recording"
+
+// stopGracePeriod is how long the denials are still collected once the
+// recording is stopped, since the collectors publish them periodically.
+const stopGracePeriod = 30 * time.Second
+
+// Add creates a new SelinuxPolicyRecording Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileSelinuxPolicyRecording{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("selinuxpolicyrecording-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource SelinuxPolicyRecording
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxPolicyRecording{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the recording policies
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxPolicy{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &selinuxv1alpha1.SelinuxPolicyRecording{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileSelinuxPolicyRecording implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileSelinuxPolicyRecording{}
+
+// ReconcileSelinuxPolicyRecording reconciles a SelinuxPolicyRecording object
+type ReconcileSelinuxPolicyRecording struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// getRecordingPolicyName gets the name of the permissive policy that the
+// workload runs with while it's being recorded.
+func getRecordingPolicyName(name string) string {
+	return name + "-recording"
+}
+
+// Reconcile installs a permissive policy for the workload while it's being
+// recorded. Once the recording is stopped, the denials collected for that
+// policy are turned into a new SelinuxPolicy, which isn't applied, and the
+// permissive policy is removed.
+func (r *ReconcileSelinuxPolicyRecording) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling SelinuxPolicyRecording")
+
+	// Fetch the SelinuxPolicyRecording instance
+	instance := &selinuxv1alpha1.SelinuxPolicyRecording{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+
+	switch instance.Status.State {
+	case selinuxv1alpha1.RecordingStateCompleted, selinuxv1alpha1.RecordingStateError:
+		return reconcile.Result{}, nil
+	case selinuxv1alpha1.RecordingStateStopping:
+		// Without a timestamp, there's nothing to wait for
+		if instance.Status.StopTimestamp != nil {
+			wait := instance.Status.StopTimestamp.Add(stopGracePeriod).Sub(time.Now())
+			if wait > 0 {
+				return reconcile.Result{Requeue: true, RequeueAfter: wait}, nil
+			}
+		}
+		return r.writePolicy(instance, reqLogger)
+	}
+
+	if instance.Spec.Stop {
+		recCopy := instance.DeepCopy()
+		now := metav1.Now()
+		recCopy.Status.State = selinuxv1alpha1.RecordingStateStopping
+		recCopy.Status.StopTimestamp = &now
+		if err := r.client.Status().Update(context.TODO(), recCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true, RequeueAfter: stopGracePeriod}, nil
+	}
+
+	return r.record(instance, reqLogger)
+}
+
+// record makes sure that the permissive policy for the recording exists.
+func (r *ReconcileSelinuxPolicyRecording) record(rec *selinuxv1alpha1.SelinuxPolicyRecording, logger logr.Logger) (reconcile.Result, error) {
+	policyName := getRecordingPolicyName(rec.Name)
+	policy := &selinuxv1alpha1.SelinuxPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      policyName,
+			Namespace: rec.Namespace,
+			Labels:    map[string]string{RecordingLabel: rec.Name},
+		},
+		Spec: selinuxv1alpha1.SelinuxPolicySpec{
+			Apply:  true,
+			Policy: inheritTemplates(rec.Spec.Templates),
+			// Denials are logged, but nothing is enforced
+			Mode: selinuxv1alpha1.PolicyModePermissive,
+		},
+	}
+	if err := controllerutil.SetControllerReference(rec, policy, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+	found := &selinuxv1alpha1.SelinuxPolicy{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating the recording SelinuxPolicy", "SelinuxPolicy.Name", policy.Name)
+		if err := r.client.Create(context.TODO(), policy); utils.IgnoreAlreadyExists(err) != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err != nil {
+		return reconcile.Result{}, err
+	} else if !metav1.IsControlledBy(found, rec) {
+		return reconcile.Result{}, r.setStatus(rec, selinuxv1alpha1.RecordingStateError,
+			fmt.Sprintf("SelinuxPolicy '%s' already exists and doesn't belong to this recording", found.Name))
+	}
+
+	usage := utils.GetPolicyUsage(policyName, rec.Namespace)
+	if rec.Status.State != selinuxv1alpha1.RecordingStateRecording || rec.Status.Usage != usage {
+		recCopy := rec.DeepCopy()
+		recCopy.Status.State = selinuxv1alpha1.RecordingStateRecording
+		recCopy.Status.Usage = usage
+		recCopy.Status.Policy = getPolicyName(rec)
+		recCopy.Status.Message = fmt.Sprintf("run the workload with the SELinux type '%s' and exercise it", usage)
+		if err := r.client.Status().Update(context.TODO(), recCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// writePolicy writes the recorded policy and removes the permissive one.
+func (r *ReconcileSelinuxPolicyRecording) writePolicy(rec *selinuxv1alpha1.SelinuxPolicyRecording, logger logr.Logger) (reconcile.Result, error) {
+	recordingPolicy := getRecordingPolicyName(rec.Name)
+	denials := &selinuxv1alpha1.SelinuxDenialList{}
+	err := r.client.List(context.TODO(), denials, client.InNamespace(rec.Namespace),
+		client.MatchingLabels{collector.PolicyLabel: recordingPolicy})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	records := make([]*avc.Record, 0, len(denials.Items))
+	for _, denial := range denials.Items {
+		records = append(records, &avc.Record{
+			Permissions: denial.Status.Permissions,
+			Source:      avc.Context{Type: denial.Spec.Source},
+			Target:      avc.Context{Type: denial.Spec.Target},
+			Class:       denial.Spec.Class,
+		})
+	}
+
+	var b strings.Builder
+	b.WriteString(inheritTemplates(rec.Spec.Templates))
+	for _, rule := range audit2allow.Translate(utils.GetPolicyName(recordingPolicy, rec.Namespace), records) {
+		b.WriteString(rule.String())
+		b.WriteString("\n")
+	}
+
+	policy := &selinuxv1alpha1.SelinuxPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getPolicyName(rec),
+			Namespace: rec.Namespace,
+			Labels:    map[string]string{RecordingLabel: rec.Name},
+		},
+		Spec: selinuxv1alpha1.SelinuxPolicySpec{
+			// Needs to be reviewed before it's applied
+			Apply:  false,
+			Policy: b.String(),
+		},
+	}
+	found := &selinuxv1alpha1.SelinuxPolicy{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating the recorded SelinuxPolicy", "SelinuxPolicy.Name", policy.Name, "denials", len(records))
+		if err := r.client.Create(context.TODO(), policy); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err != nil {
+		return reconcile.Result{}, err
+	} else if found.Labels[RecordingLabel] == rec.Name && !found.Spec.Apply {
+		// Written by a previous attempt
+		policyCopy := found.DeepCopy()
+		policyCopy.Spec.Policy = policy.Spec.Policy
+		if err := r.client.Update(context.TODO(), policyCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else {
+		return reconcile.Result{}, r.setStatus(rec, selinuxv1alpha1.RecordingStateError,
+			fmt.Sprintf("SelinuxPolicy '%s' already exists, the recorded policy wasn't written", found.Name))
+	}
+
+	// The denials are deleted along with the recording policy
+	logger.Info("Deleting the recording SelinuxPolicy", "SelinuxPolicy.Name", recordingPolicy)
+	err = r.client.Delete(context.TODO(), &selinuxv1alpha1.SelinuxPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: recordingPolicy, Namespace: rec.Namespace},
+	})
+	if utils.IgnoreNotFound(err) != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, r.setStatus(rec, selinuxv1alpha1.RecordingStateCompleted,
+		fmt.Sprintf("the recorded policy was written to SelinuxPolicy '%s', review it and set apply to install it", policy.Name))
+}
+
+func (r *ReconcileSelinuxPolicyRecording) setStatus(rec *selinuxv1alpha1.SelinuxPolicyRecording, state selinuxv1alpha1.RecordingState, msg string) error {
+	recCopy := rec.DeepCopy()
+	recCopy.Status.State = state
+	recCopy.Status.Message = msg
+	return r.client.Status().Update(context.TODO(), recCopy)
+}
+
+// getPolicyName gets the name of the policy that the recorded policy is
+// written to.
+func getPolicyName(rec *selinuxv1alpha1.SelinuxPolicyRecording) string {
+	if rec.Spec.PolicyName != "" {
+		return rec.Spec.PolicyName
+	}
+	return rec.Name
+}
+
+// inheritTemplates returns the blockinherit statements for the given
+// templates. The container template, which declares the process type, is
+// always inherited.
+func inheritTemplates(templates []string) string {
+	var b strings.Builder
+	b.WriteString("(blockinherit container)\n")
+	for _, template := range templates {
+		if template != "container" {
+			fmt.Fprintf(&b, "(blockinherit %s)\n", template)
+		}
+	}
+	return b.String()
+}
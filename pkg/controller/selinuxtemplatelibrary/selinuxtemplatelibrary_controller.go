@@ -0,0 +1,217 @@
+package selinuxtemplatelibrary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+	"github.com/JAORMX/selinux-operator/pkg/templates"
+)
+
+var log = logf.Log.WithName("controller_selinuxtemplatelibrary")
+
+// Add creates a new SelinuxTemplateLibrary Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	if err := add(mgr, newReconciler(mgr)); err != nil {
+		return err
+	}
+
+	// Publish the template libraries we ship once the caches are up
+	return mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		return wait.PollImmediateUntil(5*time.Second, func() (bool, error) {
+			if err := ensureLibraries(mgr.GetClient()); err != nil {
+				log.Error(err, "Failed to publish the template libraries")
+				return false, nil
+			}
+			return true, nil
+		}, stop)
+	}))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileSelinuxTemplateLibrary{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("selinuxtemplatelibrary-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource SelinuxTemplateLibrary
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxTemplateLibrary{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Changing the template version in the operator settings changes
+	// which library is active, so all of them need to be reconciled.
+	err = c.Watch(&source.Kind{Type: &selinuxv1alpha1.SelinuxOperatorConfig{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			for _, version := range templates.Versions() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: version}})
+			}
+			return requests
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to secondary resource ConfigMaps and requeue the owner SelinuxTemplateLibrary
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &selinuxv1alpha1.SelinuxTemplateLibrary{},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ensureLibraries creates a SelinuxTemplateLibrary object for every
+// template library that the operator ships.
+func ensureLibraries(c client.Client) error {
+	for _, version := range templates.Versions() {
+		lib, _ := templates.Get(version)
+		library := &selinuxv1alpha1.SelinuxTemplateLibrary{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: version,
+			},
+			Spec: selinuxv1alpha1.SelinuxTemplateLibrarySpec{
+				Version:   version,
+				Templates: lib.Blocks(),
+			},
+		}
+		if err := c.Create(context.TODO(), library); utils.IgnoreAlreadyExists(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blank assignment to verify that ReconcileSelinuxTemplateLibrary implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileSelinuxTemplateLibrary{}
+
+// ReconcileSelinuxTemplateLibrary reconciles a SelinuxTemplateLibrary object
+type ReconcileSelinuxTemplateLibrary struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+}
+
+// Reconcile makes sure that the ConfigMap holding the templates exists for
+// the active template library, which will get them installed on the nodes,
+// and that it doesn't exist for any other library.
+func (r *ReconcileSelinuxTemplateLibrary) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Name", request.Name)
+	reqLogger.Info("Reconciling SelinuxTemplateLibrary")
+
+	// Fetch the SelinuxTemplateLibrary instance
+	instance := &selinuxv1alpha1.SelinuxTemplateLibrary{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		return reconcile.Result{}, utils.IgnoreNotFound(err)
+	}
+
+	if _, err := operatorconfig.Fetch(context.TODO(), r.client); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	lib, ok := templates.Get(instance.Spec.Version)
+	if !ok {
+		libraryCopy := instance.DeepCopy()
+		libraryCopy.Status.State = selinuxv1alpha1.PolicyStateError
+		libraryCopy.Status.Message = fmt.Sprintf("the operator doesn't ship version '%s' of the templates", instance.Spec.Version)
+		return reconcile.Result{}, r.client.Status().Update(context.TODO(), libraryCopy)
+	}
+
+	cm := newConfigMapForLibrary(lib)
+	if instance.Spec.Version != utils.GetTemplateVersion() {
+		return r.deactivate(instance, cm, reqLogger)
+	}
+
+	if !instance.Status.Active {
+		libraryCopy := instance.DeepCopy()
+		libraryCopy.Status.Active = true
+		libraryCopy.Status.State = selinuxv1alpha1.PolicyStatePending
+		libraryCopy.Status.Message = ""
+		if err := r.client.Status().Update(context.TODO(), libraryCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(instance, cm, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Check if this cm already exists
+	foundCM := &corev1.ConfigMap{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, foundCM)
+	if err != nil && errors.IsNotFound(err) {
+		reqLogger.Info("Creating a new ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+		return reconcile.Result{}, utils.IgnoreAlreadyExists(r.client.Create(context.TODO(), cm))
+	}
+	return reconcile.Result{}, err
+}
+
+func (r *ReconcileSelinuxTemplateLibrary) deactivate(instance *selinuxv1alpha1.SelinuxTemplateLibrary, cm *corev1.ConfigMap, logger logr.Logger) (reconcile.Result, error) {
+	if err := utils.IgnoreNotFound(r.client.Delete(context.TODO(), cm)); err != nil {
+		return reconcile.Result{}, err
+	}
+	if instance.Status.Active || instance.Status.State != "" {
+		logger.Info("Deactivating template library")
+		libraryCopy := instance.DeepCopy()
+		libraryCopy.Status = selinuxv1alpha1.SelinuxTemplateLibraryStatus{}
+		if err := r.client.Status().Update(context.TODO(), libraryCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+func newConfigMapForLibrary(lib *templates.Library) *corev1.ConfigMap {
+	labels := map[string]string{
+		"templateLibrary": lib.Version,
+	}
+
+	data := map[string]string{}
+	for _, t := range lib.Templates {
+		data[t.Module+".cil"] = t.Policy
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GetTemplatesConfigMapName(lib.Version),
+			Namespace: utils.GetOperatorNamespace(),
+			Labels:    labels,
+		},
+		Data: data,
+	}
+}
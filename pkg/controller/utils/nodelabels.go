@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PolicyNodeLabelPrefix prefixes the labels that mark the nodes a policy
+// is installed on.
+const PolicyNodeLabelPrefix = "policy.selinux.openshift.io/"
+
+// maxLabelNameLength is the longest name a label key can have, after its
+// prefix.
+const maxLabelNameLength = 63
+
+// GetPolicyNodeLabel gets the label of the nodes that the given policy is
+// installed on. Names that don't fit in a label are shortened, and made
+// unique with a hash.
+func GetPolicyNodeLabel(name, ns string) string {
+	labelName := GetPolicyName(name, ns)
+	if len(labelName) > maxLabelNameLength {
+		hashed := hashName(labelName)[:10]
+		labelName = labelName[:maxLabelNameLength-len(hashed)-1] + "-" + hashed
+	}
+	return PolicyNodeLabelPrefix + labelName
+}
+
+// SyncNodeLabel sets the given label on the nodes in the set, and removes
+// it from every other node.
+func SyncNodeLabel(ctx context.Context, c client.Client, label string, nodes map[string]bool) error {
+	nodesList := &corev1.NodeList{}
+	if err := c.List(ctx, nodesList); err != nil {
+		return err
+	}
+	for i := range nodesList.Items {
+		node := &nodesList.Items[i]
+		_, hasLabel := node.Labels[label]
+		if hasLabel == nodes[node.Name] {
+			continue
+		}
+		patch := client.MergeFrom(node.DeepCopy())
+		if hasLabel {
+			delete(node.Labels, label)
+		} else {
+			if node.Labels == nil {
+				node.Labels = map[string]string{}
+			}
+			node.Labels[label] = "true"
+		}
+		if err := IgnoreNotFound(c.Patch(ctx, node, patch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
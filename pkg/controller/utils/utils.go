@@ -2,6 +2,7 @@ package utils
 
 import (
 	hash "crypto/sha1"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"strings"
@@ -9,8 +10,39 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
 )
 
+// PolicySnapshotConfigMapName is the name of the ConfigMap, in the operator's
+// namespace, that describes the types, attributes and classes of the policy
+// loaded on the nodes.
+const PolicySnapshotConfigMapName = "selinux-policy-snapshot"
+
+// PolicyModulePriority is the priority at which the policy modules are
+// installed and removed.
+const PolicyModulePriority = 400
+
+// ChecksumAnnotation holds the SHA-256 checksum of the policy module that
+// a ConfigMap or pod was created for.
+const ChecksumAnnotation = "selinux.openshift.io/checksum"
+
+// SourceChecksumAnnotation holds the SHA-256 checksum of the policy source
+// that the module shipped in a ConfigMap was built from.
+const SourceChecksumAnnotation = "selinux.openshift.io/source-checksum"
+
+// AuthorAnnotation holds the user that last changed the content of a
+// SelinuxPolicy. It's set by the operator's admission webhook.
+const AuthorAnnotation = "selinux.openshift.io/author"
+
+// ApproverAnnotation holds the user that created a SelinuxPolicyApproval.
+// It's set by the operator's admission webhook.
+const ApproverAnnotation = "selinux.openshift.io/approver"
+
+// PolicyReadinessGate is the condition of the pods that are ready once the
+// policies they run with are installed on their node.
+const PolicyReadinessGate corev1.PodConditionType = "selinux.openshift.io/policy-installed"
+
 // GetPolicyName gets the policy module name in the format that
 // we're expecting for parsing.
 func GetPolicyName(name, ns string) string {
@@ -23,6 +55,51 @@ func GetPolicyUsage(name, ns string) string {
 	return GetPolicyName(name, ns) + ".process"
 }
 
+// ParsePolicyUsage gets the name and namespace of the policy from the type
+// that pods use to run with it. It returns false if the type doesn't
+// belong to a SelinuxPolicy.
+func ParsePolicyUsage(usage string) (string, string, bool) {
+	if !strings.HasSuffix(usage, ".process") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(usage, ".process"), "_")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// GetPodPolicyNames gets the names of the policies, in the given namespace,
+// that the pod or any of its containers run with.
+func GetPodPolicyNames(pod *corev1.Pod, ns string) []string {
+	var opts []*corev1.SELinuxOptions
+	if pod.Spec.SecurityContext != nil {
+		opts = append(opts, pod.Spec.SecurityContext.SELinuxOptions)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.SecurityContext != nil {
+			opts = append(opts, container.SecurityContext.SELinuxOptions)
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.SecurityContext != nil {
+			opts = append(opts, container.SecurityContext.SELinuxOptions)
+		}
+	}
+
+	var names []string
+	for _, o := range opts {
+		if o == nil {
+			continue
+		}
+		name, policyNs, ok := ParsePolicyUsage(o.Type)
+		if ok && policyNs == ns && !SliceContainsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // GetPolicyK8sName gets the policy name in a format that's OK for k8s names.
 func GetPolicyK8sName(name, ns string) string {
 	return name + "-" + ns
@@ -40,9 +117,20 @@ func GetInstallerPodName(name, ns string, node *corev1.Node) string {
 	// policy-installer
 	parsedNodeName := parseNodeName(node.Name)
 	podname := GetPolicyK8sName(name, ns) + "-" + parsedNodeName
+	return hashName(podname)
+}
+
+// GetTemplatesInstallerPodName gets the name of the pod that installs the
+// given version of the udica templates on a node.
+func GetTemplatesInstallerPodName(version string, node *corev1.Node) string {
+	parsedNodeName := parseNodeName(node.Name)
+	podname := GetTemplatesConfigMapName(version) + "-" + parsedNodeName
+	return hashName(podname)
+}
 
+func hashName(name string) string {
 	hasher := hash.New()
-	io.WriteString(hasher, podname)
+	io.WriteString(hasher, name)
 	return fmt.Sprintf("%x", hasher.Sum(nil))
 }
 
@@ -51,11 +139,50 @@ func GetPolicyConfigMapName(name, ns string) string {
 	return namePrefix + "-" + GetPolicyK8sName(name, ns)
 }
 
+// GetPolicyBuildConfigMapName gets the name of the ConfigMap holding the
+// policy module while it's being built.
+func GetPolicyBuildConfigMapName(name, ns string) string {
+	return "policy-build-for-" + GetPolicyK8sName(name, ns)
+}
+
+// GetBuilderPodName gets the name of the pod that builds the policy module.
+func GetBuilderPodName(name, ns string) string {
+	return "policy-builder-" + hashName(GetPolicyK8sName(name, ns))
+}
+
+// GetPolicyModuleFile gets the name of the file, in the given ConfigMap,
+// that holds the policy module.
+func GetPolicyModuleFile(cm *corev1.ConfigMap, name, ns string) string {
+	moduleName := GetPolicyName(name, ns)
+	if _, ok := cm.BinaryData[moduleName+".pp"]; ok {
+		return moduleName + ".pp"
+	}
+	return moduleName + ".cil"
+}
+
+// GetChecksum gets the SHA-256 checksum of a policy module, in the format
+// that sha256sum expects.
+func GetChecksum(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// GetTemplatesConfigMapName gets the name of the ConfigMap holding the
+// given version of the udica templates.
+func GetTemplatesConfigMapName(version string) string {
+	return "udica-templates-" + strings.ReplaceAll(version, ".", "-")
+}
+
+// GetTemplateVersion gets the version of the udica templates that's
+// installed on the nodes.
+func GetTemplateVersion() string {
+	return operatorconfig.Get().TemplateVersion
+}
+
 // GetOperatorNamespace gets the namespace that the operator is currently running on.
 func GetOperatorNamespace() string {
 	operatorNs, err := k8sutil.GetOperatorNamespace()
 	if err != nil {
-		return "openshift-selinux-operator"
+		return operatorconfig.Get().FallbackNamespace
 	}
 	return operatorNs
 }
@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"strings"
+)
+
+// fileContext maps a path, and everything below it unless overridden by a
+// more specific entry, to the SELinux type it's labeled with on the nodes.
+type fileContext struct {
+	path     string
+	typeName string
+}
+
+// fileContexts is a subset of the file contexts of the targeted policy,
+// covering the paths that are commonly mounted into containers. It stands
+// in for the matchpathcon lookups that udica does on the host.
+var fileContexts = []fileContext{
+	{"/", "root_t"},
+	{"/boot", "boot_t"},
+	{"/dev", "device_t"},
+	{"/etc", "etc_t"},
+	{"/etc/cni", "container_config_t"},
+	{"/etc/containers", "container_config_t"},
+	{"/etc/kubernetes", "kubernetes_file_t"},
+	{"/etc/pki", "cert_t"},
+	{"/etc/ssl", "cert_t"},
+	{"/home", "home_root_t"},
+	{"/lib/modules", "modules_object_t"},
+	{"/media", "mnt_t"},
+	{"/mnt", "mnt_t"},
+	{"/opt", "usr_t"},
+	{"/proc", "proc_t"},
+	{"/root", "admin_home_t"},
+	{"/run", "var_run_t"},
+	{"/run/containerd", "container_var_run_t"},
+	{"/run/containers", "container_var_run_t"},
+	{"/run/crio", "container_var_run_t"},
+	{"/run/docker.sock", "container_var_run_t"},
+	{"/run/libvirt", "virt_var_run_t"},
+	{"/srv", "var_t"},
+	{"/sys", "sysfs_t"},
+	{"/sys/fs/cgroup", "cgroup_t"},
+	{"/sys/fs/selinux", "security_t"},
+	{"/tmp", "tmp_t"},
+	{"/tmp/.X11-unix", "xdm_tmp_t"},
+	{"/usr", "usr_t"},
+	{"/usr/bin", "bin_t"},
+	{"/usr/lib/modules", "modules_object_t"},
+	{"/usr/sbin", "bin_t"},
+	{"/var", "var_t"},
+	{"/var/cache", "var_t"},
+	{"/var/lib", "var_lib_t"},
+	{"/var/lib/containers", "container_var_lib_t"},
+	{"/var/lib/docker", "container_var_lib_t"},
+	{"/var/lib/etcd", "container_var_lib_t"},
+	{"/var/lib/kubelet", "container_var_lib_t"},
+	{"/var/lib/kubelet/pods", "container_file_t"},
+	{"/var/lib/mysql", "mysqld_db_t"},
+	{"/var/lib/pgsql", "postgresql_db_t"},
+	{"/var/log", "var_log_t"},
+	{"/var/log/audit", "auditd_log_t"},
+	{"/var/log/containers", "container_log_t"},
+	{"/var/log/journal", "var_log_t"},
+	{"/var/log/pods", "container_log_t"},
+	{"/var/run", "var_run_t"},
+	{"/var/run/containerd", "container_var_run_t"},
+	{"/var/run/containers", "container_var_run_t"},
+	{"/var/run/crio", "container_var_run_t"},
+	{"/var/run/docker.sock", "container_var_run_t"},
+	{"/var/run/libvirt", "virt_var_run_t"},
+	{"/var/spool", "var_spool_t"},
+	{"/var/tmp", "tmp_t"},
+	{"/var/www", "httpd_sys_content_t"},
+}
+
+// deviceContexts maps device nodes to their types. Devices that aren't
+// listed are labeled device_t.
+var deviceContexts = []fileContext{
+	{"/dev/dri", "dri_device_t"},
+	{"/dev/fuse", "fuse_device_t"},
+	{"/dev/kvm", "kvm_device_t"},
+	{"/dev/net/tun", "tun_tap_device_t"},
+	{"/dev/null", "null_device_t"},
+	{"/dev/random", "random_device_t"},
+	{"/dev/tty", "devtty_t"},
+	{"/dev/urandom", "urandom_device_t"},
+	{"/dev/vfio", "vfio_device_t"},
+	{"/dev/zero", "zero_device_t"},
+}
+
+// blockDevicePrefixes are the names of the block devices, as opposed to
+// character devices, under /dev.
+var blockDevicePrefixes = []string{"/dev/sd", "/dev/vd", "/dev/xvd", "/dev/nvme", "/dev/loop", "/dev/dm-", "/dev/mapper/"}
+
+// cleanPath removes trailing slashes from the given path.
+func cleanPath(path string) string {
+	if path != "/" {
+		path = strings.TrimRight(path, "/")
+	}
+	return path
+}
+
+// isBelow returns true if path is the given directory or is inside it.
+func isBelow(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
+
+// lookupContext returns the type of the given path, according to the given
+// contexts.
+func lookupContext(contexts []fileContext, path, defaultType string) string {
+	match, typeName := "", defaultType
+	for _, fc := range contexts {
+		if isBelow(path, fc.path) && len(fc.path) > len(match) {
+			match, typeName = fc.path, fc.typeName
+		}
+	}
+	return typeName
+}
+
+// typesForPath returns the types that a container mounting the given path
+// needs access to: the type of the path itself and the types of the known
+// paths below it.
+func typesForPath(path string) []string {
+	types := []string{lookupContext(fileContexts, path, "default_t")}
+	for _, fc := range fileContexts {
+		if fc.path != path && isBelow(fc.path, path) && !containsString(types, fc.typeName) {
+			types = append(types, fc.typeName)
+		}
+	}
+	return types
+}
+
+// portContext maps a port number to its type.
+type portContext struct {
+	protocol string
+	port     int32
+	typeName string
+}
+
+var portContexts = []portContext{
+	{"tcp", 21, "ftp_port_t"},
+	{"tcp", 22, "ssh_port_t"},
+	{"tcp", 25, "smtp_port_t"},
+	{"tcp", 53, "dns_port_t"},
+	{"udp", 53, "dns_port_t"},
+	{"udp", 67, "dhcpd_port_t"},
+	{"udp", 69, "tftp_port_t"},
+	{"tcp", 80, "http_port_t"},
+	{"tcp", 81, "http_port_t"},
+	{"tcp", 110, "pop_port_t"},
+	{"udp", 123, "ntp_port_t"},
+	{"tcp", 143, "pop_port_t"},
+	{"udp", 161, "snmp_port_t"},
+	{"tcp", 389, "ldap_port_t"},
+	{"tcp", 443, "http_port_t"},
+	{"udp", 514, "syslogd_port_t"},
+	{"tcp", 636, "ldap_port_t"},
+	{"tcp", 2049, "nfs_port_t"},
+	{"tcp", 3306, "mysqld_port_t"},
+	{"tcp", 5432, "postgresql_port_t"},
+	{"tcp", 6379, "redis_port_t"},
+	{"tcp", 8008, "http_port_t"},
+	{"tcp", 8009, "http_port_t"},
+	{"tcp", 8080, "http_cache_port_t"},
+	{"tcp", 8443, "http_port_t"},
+	{"tcp", 9000, "http_port_t"},
+	{"tcp", 9090, "websm_port_t"},
+	{"tcp", 11211, "memcache_port_t"},
+	{"udp", 11211, "memcache_port_t"},
+	{"tcp", 27017, "mongod_port_t"},
+}
+
+// lookupPort returns the type of the given port. Ports that aren't listed
+// get the type of the range they're in.
+func lookupPort(protocol string, port int32) string {
+	for _, pc := range portContexts {
+		if pc.protocol == protocol && pc.port == port {
+			return pc.typeName
+		}
+	}
+	switch {
+	case port < 512:
+		return "reserved_port_t"
+	case port < 1024:
+		return "hi_reserved_port_t"
+	case port >= 32768 && port <= 60999:
+		return "ephemeral_port_t"
+	default:
+		return "unreserved_port_t"
+	}
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
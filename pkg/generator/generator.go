@@ -0,0 +1,255 @@
+// Package generator generates udica-style SELinux policies for pods. Like
+// udica, the policies build on the base templates and only add the
+// accesses that the pod's host volumes, ports, capabilities and devices
+// call for, so they're meant as a starting point to be reviewed.
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The permissions that udica grants on mounted paths
+const (
+	dirRW      = "open read getattr lock search ioctl add_name remove_name write"
+	fileRW     = "getattr read write append ioctl lock map open create"
+	sockFileRW = "getattr read write append open"
+	dirRO      = "open read getattr lock search ioctl"
+	fileRO     = "getattr read ioctl lock open"
+	sockFileRO = "getattr read open"
+	deviceRW   = "getattr read write append ioctl lock open"
+	deviceRO   = "getattr read ioctl lock open"
+)
+
+// The order in which the base templates are inherited
+var templateOrder = []string{
+	"container",
+	"net_container",
+	"home_container",
+	"log_container",
+	"config_container",
+	"tty_container",
+	"virt_container",
+	"x_container",
+}
+
+// The capabilities that belong to the capability2 class
+var capabilities2 = []string{"mac_override", "mac_admin", "syslog", "wake_alarm", "block_suspend", "audit_read", "perfmon", "bpf", "checkpoint_restore"}
+
+// All the capabilities, for containers that add "ALL" of them
+var allCapabilities = []string{"chown", "dac_override", "dac_read_search", "fowner", "fsetid", "kill", "setgid", "setuid", "setpcap",
+	"linux_immutable", "net_bind_service", "net_broadcast", "net_admin", "net_raw", "ipc_lock", "ipc_owner", "sys_module", "sys_rawio",
+	"sys_chroot", "sys_ptrace", "sys_pacct", "sys_admin", "sys_boot", "sys_nice", "sys_resource", "sys_time", "sys_tty_config", "mknod",
+	"lease", "audit_write", "audit_control", "setfcap"}
+
+type mount struct {
+	path     string
+	writable bool
+	device   bool
+	block    bool
+}
+
+type policy struct {
+	templates map[string]bool
+	rules     []string
+	seen      map[string]bool
+}
+
+func (p *policy) inherit(block string) {
+	p.templates[block] = true
+}
+
+func (p *policy) allow(target, class, perms string) {
+	rule := fmt.Sprintf("(allow process %s ( %s ( %s )))", target, class, perms)
+	if !p.seen[rule] {
+		p.seen[rule] = true
+		p.rules = append(p.rules, rule)
+	}
+}
+
+func (p *policy) String() string {
+	var b strings.Builder
+	for _, block := range templateOrder {
+		if p.templates[block] {
+			fmt.Fprintf(&b, "(blockinherit %s)\n", block)
+		}
+	}
+	for _, rule := range p.rules {
+		b.WriteString(rule)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Generate returns the CIL policy for the containers of the given pod spec.
+// The policy is meant to be used as the policy of a SelinuxPolicy, which
+// wraps it in the policy's block. The output is stable, so it only changes
+// when the relevant parts of the pod spec change.
+func Generate(spec *corev1.PodSpec) string {
+	p := &policy{
+		templates: map[string]bool{"container": true},
+		seen:      map[string]bool{},
+	}
+
+	hostPaths := map[string]*corev1.HostPathVolumeSource{}
+	for _, vol := range spec.Volumes {
+		if vol.HostPath != nil {
+			hostPaths[vol.Name] = vol.HostPath
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	mounts := map[string]*mount{}
+	var ports []corev1.ContainerPort
+	var caps []string
+	for _, c := range containers {
+		for _, vm := range c.VolumeMounts {
+			hostPath, ok := hostPaths[vm.Name]
+			if !ok {
+				continue
+			}
+			path := cleanPath(hostPath.Path)
+			if vm.SubPath != "" {
+				path = cleanPath(path + "/" + vm.SubPath)
+			}
+			m, ok := mounts[path]
+			if !ok {
+				m = newMount(path, hostPath.Type)
+				mounts[path] = m
+			}
+			m.writable = m.writable || !vm.ReadOnly
+		}
+		ports = append(ports, c.Ports...)
+		if c.SecurityContext != nil && c.SecurityContext.Capabilities != nil {
+			for _, capability := range c.SecurityContext.Capabilities.Add {
+				caps = append(caps, normalizeCapability(string(capability))...)
+			}
+		}
+		if c.TTY {
+			p.inherit("tty_container")
+		}
+	}
+
+	addPorts(p, ports)
+	paths := make([]string, 0, len(mounts))
+	for path := range mounts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		addMount(p, mounts[path])
+	}
+	addCapabilities(p, caps)
+	return p.String()
+}
+
+func newMount(path string, hostPathType *corev1.HostPathType) *mount {
+	m := &mount{path: path}
+	if hostPathType != nil {
+		switch *hostPathType {
+		case corev1.HostPathCharDev:
+			m.device = true
+		case corev1.HostPathBlockDev:
+			m.device, m.block = true, true
+		}
+	}
+	if path != "/dev" && isBelow(path, "/dev") {
+		m.device = true
+		for _, prefix := range blockDevicePrefixes {
+			if strings.HasPrefix(path, prefix) {
+				m.block = true
+			}
+		}
+	}
+	return m
+}
+
+func addPorts(p *policy, ports []corev1.ContainerPort) {
+	if len(ports) == 0 {
+		return
+	}
+	p.inherit("net_container")
+	sort.SliceStable(ports, func(i, j int) bool { return ports[i].ContainerPort < ports[j].ContainerPort })
+	for _, port := range ports {
+		protocol := strings.ToLower(string(port.Protocol))
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		p.allow(lookupPort(protocol, port.ContainerPort), protocol+"_socket", "name_bind")
+	}
+}
+
+func addMount(p *policy, m *mount) {
+	switch {
+	case m.device:
+		class := "chr_file"
+		if m.block {
+			class = "blk_file"
+		}
+		perms := deviceRO
+		if m.writable {
+			perms = deviceRW
+		}
+		p.allow(lookupContext(deviceContexts, m.path, "device_t"), class, perms)
+		return
+	case isBelow(m.path, "/home"):
+		p.inherit("home_container")
+		return
+	case isBelow(m.path, "/var/log"):
+		p.inherit("log_container")
+		return
+	case m.path == "/etc" && !m.writable:
+		p.inherit("config_container")
+		return
+	case isBelow(m.path, "/tmp/.X11-unix"):
+		p.inherit("x_container")
+		return
+	case isBelow(m.path, "/run/libvirt") || isBelow(m.path, "/var/run/libvirt"):
+		p.inherit("virt_container")
+		return
+	}
+
+	for _, typeName := range typesForPath(m.path) {
+		if m.writable {
+			p.allow(typeName, "dir", dirRW)
+			p.allow(typeName, "file", fileRW)
+			p.allow(typeName, "sock_file", sockFileRW)
+		} else {
+			p.allow(typeName, "dir", dirRO)
+			p.allow(typeName, "file", fileRO)
+			p.allow(typeName, "sock_file", sockFileRO)
+		}
+	}
+}
+
+func addCapabilities(p *policy, caps []string) {
+	var capability, capability2 []string
+	for _, c := range caps {
+		if containsString(capabilities2, c) {
+			if !containsString(capability2, c) {
+				capability2 = append(capability2, c)
+			}
+		} else if !containsString(capability, c) {
+			capability = append(capability, c)
+		}
+	}
+	if len(capability) > 0 {
+		p.allow("process", "capability", strings.Join(capability, " "))
+	}
+	if len(capability2) > 0 {
+		p.allow("process", "capability2", strings.Join(capability2, " "))
+	}
+}
+
+// normalizeCapability turns a capability, as found in a container's
+// security context, into the permissions of the capability classes.
+func normalizeCapability(capability string) []string {
+	name := strings.ToLower(strings.TrimPrefix(strings.ToUpper(capability), "CAP_"))
+	if name == "all" {
+		return allCapabilities
+	}
+	return []string{name}
+}
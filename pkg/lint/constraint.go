@@ -0,0 +1,246 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/cil"
+	"github.com/JAORMX/selinux-operator/pkg/templates"
+)
+
+// CheckConstraint returns how the given policy breaks the constraint, one
+// message per offending statement. The snapshot of the policy loaded on
+// the nodes tells which types its attributes include. It may be nil, in
+// which case access to attributes that aren't declared by the policy is
+// never allowed.
+func CheckConstraint(policy string, constraint *selinuxv1alpha1.SelinuxPolicyConstraintSpec, snapshot *cil.Snapshot) ([]string, error) {
+	stmts, err := cil.Parse(policy)
+	if err != nil {
+		return nil, err
+	}
+	cps := collectClassPermissions(stmts)
+	targets := newTargetChecker(stmts, constraint.ForbiddenTypes, snapshot)
+
+	var violations []string
+	report := func(line int, format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf("line %d: %s", line, fmt.Sprintf(format, args...)))
+	}
+	walk(stmts, func(stmt *cil.Node) {
+		args := stmt.Args()
+		switch stmt.Keyword() {
+		case "blockinherit":
+			for _, template := range constraint.ForbiddenTemplates {
+				if len(args) == 1 && args[0].Atom == template {
+					report(stmt.Line, "inheriting the template '%s' is forbidden", template)
+				}
+			}
+			return
+		case "typeattributeset":
+			// Allowing the attribute would allow its types, so the
+			// forbidden types can't be added to one. The complement
+			// of a set and "all" can include any type.
+			if len(args) != 2 {
+				return
+			}
+			members := atoms(args[1])
+			for _, forbidden := range constraint.ForbiddenTypes {
+				if contains(members, forbidden) || contains(members, "not") || contains(members, "all") {
+					report(stmt.Line, "the attribute '%s' can include '%s', which is forbidden", args[0].Atom, forbidden)
+				}
+			}
+			return
+		case "typealiasactual":
+			if len(args) != 2 {
+				return
+			}
+			for _, forbidden := range constraint.ForbiddenTypes {
+				if args[1].Atom == forbidden {
+					report(stmt.Line, "aliasing '%s' is forbidden", forbidden)
+				}
+			}
+			return
+		case "call":
+			// The macro can allow access to the types it's passed
+			if len(args) != 2 {
+				return
+			}
+			for _, forbidden := range constraint.ForbiddenTypes {
+				if contains(atoms(args[1]), forbidden) {
+					report(stmt.Line, "passing '%s' to a macro is forbidden", forbidden)
+				}
+			}
+			return
+		}
+		args, ok := allowArgs(stmt)
+		if !ok {
+			return
+		}
+		for _, forbidden := range constraint.ForbiddenTypes {
+			if contains(atoms(args[1]), forbidden) {
+				report(stmt.Line, "allowing access to '%s' is forbidden", forbidden)
+			}
+		}
+		// Attributes and complements can include the forbidden types
+		// without naming them
+		for _, target := range atoms(args[1]) {
+			if reason := targets.check(target, map[string]bool{}); reason != "" {
+				report(stmt.Line, "allowing access to '%s' is forbidden, since %s", args[1].String(), reason)
+				break
+			}
+		}
+		for _, cp := range classPerms(args[2], cps) {
+			if contains(constraint.ForbiddenClasses, cp.class) {
+				report(stmt.Line, "allowing access to the class '%s' is forbidden", cp.class)
+			}
+			for _, forbidden := range constraint.ForbiddenPermissions {
+				if cp.has(forbidden) {
+					report(stmt.Line, "allowing the permission '%s' is forbidden", forbidden)
+				}
+			}
+		}
+	})
+	return violations, nil
+}
+
+// targetChecker tells whether the targets of allow rules can include the
+// forbidden types.
+type targetChecker struct {
+	forbidden []string
+	snapshot  *cil.Snapshot
+	// The types declared by the policy or inherited from templates
+	types map[string]bool
+	// The attributes declared by the policy, and the type expressions
+	// that were added to them
+	attributes map[string][]*cil.Node
+}
+
+func newTargetChecker(stmts []*cil.Node, forbidden []string, snapshot *cil.Snapshot) *targetChecker {
+	c := &targetChecker{
+		forbidden:  forbidden,
+		snapshot:   snapshot,
+		types:      map[string]bool{},
+		attributes: map[string][]*cil.Node{},
+	}
+	walk(stmts, func(stmt *cil.Node) {
+		args := stmt.Args()
+		if len(args) == 0 || args[0].IsList {
+			return
+		}
+		switch stmt.Keyword() {
+		case "type":
+			c.types[args[0].Atom] = true
+		case "typeattribute":
+			if _, ok := c.attributes[args[0].Atom]; !ok {
+				c.attributes[args[0].Atom] = nil
+			}
+		case "typeattributeset":
+			if len(args) == 2 {
+				c.attributes[args[0].Atom] = append(c.attributes[args[0].Atom], args[1])
+			}
+		case "blockinherit":
+			for t := range templateTypes(args[0].Atom) {
+				c.types[t] = true
+			}
+		}
+	})
+	return c
+}
+
+// check returns why the target can include a forbidden type, or an empty
+// string if it can't. Forbidden types that are named directly aren't
+// reported, since the caller does. seen holds the attributes of the
+// policy that were already checked, so cycles end.
+func (c *targetChecker) check(target string, seen map[string]bool) string {
+	if len(c.forbidden) == 0 {
+		return ""
+	}
+	switch {
+	case target == "not" || target == "all":
+		return "it can include any type"
+	case expressionOperators[target], target == "self", contains(c.forbidden, target), c.types[target]:
+		return ""
+	}
+	if members, ok := c.attributes[target]; ok {
+		if seen[target] {
+			return ""
+		}
+		seen[target] = true
+		for _, expr := range members {
+			for _, member := range atoms(expr) {
+				if contains(c.forbidden, member) {
+					return fmt.Sprintf("it includes '%s'", member)
+				}
+				if reason := c.check(member, seen); reason != "" {
+					return reason
+				}
+			}
+		}
+		return ""
+	}
+	if c.snapshot != nil && c.snapshot.Types[target] {
+		return ""
+	}
+	if c.snapshot != nil && c.snapshot.Attributes[target] {
+		types, ok := c.snapshot.AttributeTypes[target]
+		if !ok {
+			return "the policy snapshot doesn't show which types it includes"
+		}
+		for _, forbidden := range c.forbidden {
+			if types[forbidden] {
+				return fmt.Sprintf("it includes '%s'", forbidden)
+			}
+			// Forbidden attributes can't share types with it
+			for _, t := range sortedKeys(c.snapshot.AttributeTypes[forbidden]) {
+				if types[t] {
+					return fmt.Sprintf("it includes '%s', which is in '%s'", t, forbidden)
+				}
+			}
+		}
+		return ""
+	}
+	// The policy loaded on the nodes doesn't tell. Types are named
+	// with a "_t" suffix, so anything else could be an attribute.
+	name := target[strings.LastIndex(target, ".")+1:]
+	if strings.HasSuffix(name, "_t") || (name != target && c.types[name]) {
+		return ""
+	}
+	return "it might be an attribute that includes forbidden types"
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expressionOperators are the operators of type expressions that can't
+// include types that aren't listed.
+var expressionOperators = map[string]bool{"and": true, "or": true, "xor": true}
+
+// templateTypes returns the types declared by the udica template with the
+// given block name, in any version of the templates.
+func templateTypes(block string) map[string]bool {
+	result := map[string]bool{}
+	for _, version := range templates.Versions() {
+		lib, _ := templates.Get(version)
+		tmpl, ok := lib.Lookup(block)
+		if !ok {
+			continue
+		}
+		stmts, err := cil.Parse(tmpl.Policy)
+		if err != nil {
+			continue
+		}
+		walk(stmts, func(stmt *cil.Node) {
+			if args := stmt.Args(); stmt.Keyword() == "type" && len(args) == 1 && !args[0].IsList {
+				result[args[0].Atom] = true
+			}
+		})
+	}
+	return result
+}
@@ -0,0 +1,220 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/cil"
+)
+
+func TestCheckConstraint(t *testing.T) {
+	constraint := &selinuxv1alpha1.SelinuxPolicyConstraintSpec{
+		ForbiddenTypes:       []string{"shadow_t", "container_runtime_t"},
+		ForbiddenClasses:     []string{"security"},
+		ForbiddenPermissions: []string{"sys_module", "write"},
+		ForbiddenTemplates:   []string{"x_container"},
+	}
+	snapshot := cil.ParseSnapshot(map[string]string{
+		"types":      "shadow_t container_runtime_t container_file_t etc_t var_log_t",
+		"attributes": "file_type logfile runtime_domain unknown_attr",
+		"attributeTypes": `file_type shadow_t container_file_t etc_t var_log_t
+logfile var_log_t
+runtime_domain container_runtime_t`,
+	})
+
+	tests := []struct {
+		name   string
+		policy string
+		// Defaults to the constraint above
+		constraint *selinuxv1alpha1.SelinuxPolicyConstraintSpec
+		snapshot   *cil.Snapshot
+		want       []string
+	}{
+		{
+			name: "allowed policy",
+			policy: `(blockinherit container)
+(type data)
+(allow process data (file (read open)))
+(allow process container_file_t (dir (read search)))
+(allow process self (process (signal)))`,
+		},
+		{
+			name:   "forbidden type",
+			policy: `(allow process shadow_t (file (read)))`,
+			want:   []string{"line 1: allowing access to 'shadow_t' is forbidden"},
+		},
+		{
+			name:   "forbidden type in an expression",
+			policy: `(allow process (and shadow_t etc_t) (file (read)))`,
+			want:   []string{"line 1: allowing access to 'shadow_t' is forbidden"},
+		},
+		{
+			name:   "complement of a type",
+			policy: `(allow process (not container_file_t) (file (read)))`,
+			want:   []string{"line 1: allowing access to '(not container_file_t)' is forbidden, since it can include any type"},
+		},
+		{
+			name:   "system attribute without a snapshot",
+			policy: `(allow process file_type (file (read)))`,
+			want:   []string{"line 1: allowing access to 'file_type' is forbidden, since it might be an attribute that includes forbidden types"},
+		},
+		{
+			name:     "system attribute that includes a forbidden type",
+			policy:   `(allow process file_type (file (read)))`,
+			snapshot: snapshot,
+			want:     []string{"line 1: allowing access to 'file_type' is forbidden, since it includes 'shadow_t'"},
+		},
+		{
+			name:     "system attribute that excludes the forbidden types",
+			policy:   `(allow process logfile (file (read)))`,
+			snapshot: snapshot,
+		},
+		{
+			name:     "system attribute with unknown types",
+			policy:   `(allow process unknown_attr (file (read)))`,
+			snapshot: snapshot,
+			want:     []string{"line 1: allowing access to 'unknown_attr' is forbidden, since the policy snapshot doesn't show which types it includes"},
+		},
+		{
+			name:       "system attribute that shares types with a forbidden attribute",
+			policy:     `(allow process file_type (file (read)))`,
+			constraint: &selinuxv1alpha1.SelinuxPolicyConstraintSpec{ForbiddenTypes: []string{"secret_type"}},
+			snapshot: cil.ParseSnapshot(map[string]string{
+				"attributes": "file_type secret_type",
+				"attributeTypes": `file_type etc_t secret_t
+secret_type secret_t`,
+			}),
+			want: []string{"line 1: allowing access to 'file_type' is forbidden, since it includes 'secret_t', which is in 'secret_type'"},
+		},
+		{
+			name: "local attribute of local types",
+			policy: `(type data)
+(typeattribute files)
+(typeattributeset files (data))
+(allow process files (file (read)))`,
+		},
+		{
+			name: "local attribute containing a system attribute",
+			policy: `(typeattribute files)
+(typeattributeset files (file_type))
+(allow process files (file (read)))`,
+			snapshot: snapshot,
+			want:     []string{"line 3: allowing access to 'files' is forbidden, since it includes 'shadow_t'"},
+		},
+		{
+			name: "local attributes containing each other",
+			policy: `(typeattribute a)
+(typeattribute b)
+(typeattributeset a (b))
+(typeattributeset b (a file_type))
+(allow process a (file (read)))`,
+			want: []string{"line 5: allowing access to 'a' is forbidden, since it might be an attribute that includes forbidden types"},
+		},
+		{
+			name: "local attribute containing a forbidden type",
+			policy: `(typeattribute files)
+(typeattributeset files (shadow_t))
+(allow process files (file (read)))`,
+			want: []string{
+				"line 2: the attribute 'files' can include 'shadow_t', which is forbidden",
+				"line 3: allowing access to 'files' is forbidden, since it includes 'shadow_t'",
+			},
+		},
+		{
+			name:   "type of another policy",
+			policy: `(blockinherit container) (allow process other_default.process (unix_stream_socket (connectto)))`,
+		},
+		{
+			name:   "forbidden class",
+			policy: `(allow process self (security (compute_av)))`,
+			want:   []string{"line 1: allowing access to the class 'security' is forbidden"},
+		},
+		{
+			name:   "forbidden permission",
+			policy: `(allow process self (capability (sys_module)))`,
+			want:   []string{"line 1: allowing the permission 'sys_module' is forbidden"},
+		},
+		{
+			name:   "complement of the permissions",
+			policy: `(allow process etc_t (file (not (read))))`,
+			want: []string{
+				"line 1: allowing the permission 'sys_module' is forbidden",
+				"line 1: allowing the permission 'write' is forbidden",
+			},
+		},
+		{
+			name:   "all permissions",
+			policy: `(allow process etc_t (file (all)))`,
+			want: []string{
+				"line 1: allowing the permission 'sys_module' is forbidden",
+				"line 1: allowing the permission 'write' is forbidden",
+			},
+		},
+		{
+			name: "nested class permission sets",
+			policy: `(classpermission inner)
+(classpermissionset inner (capability (sys_module)))
+(classpermission outer)
+(classpermissionset outer inner)
+(allow process self outer)`,
+			want: []string{"line 5: allowing the permission 'sys_module' is forbidden"},
+		},
+		{
+			name: "classmap",
+			policy: `(classmap files (reader writer))
+(classmapping files reader (file (read open)))
+(classmapping files writer (file (write)))
+(allow process etc_t (files (reader)))
+(allow process etc_t (files (writer)))`,
+			want: []string{"line 5: allowing the permission 'write' is forbidden"},
+		},
+		{
+			name: "classmap to a named class permission",
+			policy: `(classpermission admin)
+(classpermissionset admin (security (setenforce)))
+(classmap ops (toggle))
+(classmapping ops toggle admin)
+(allow process self (ops (all)))`,
+			want: []string{"line 5: allowing access to the class 'security' is forbidden"},
+		},
+		{
+			name:   "forbidden template",
+			policy: `(blockinherit x_container)`,
+			want:   []string{"line 1: inheriting the template 'x_container' is forbidden"},
+		},
+		{
+			name:   "forbidden type added to a system attribute",
+			policy: `(typeattributeset file_type (not etc_t))`,
+			want: []string{
+				"line 1: the attribute 'file_type' can include 'shadow_t', which is forbidden",
+				"line 1: the attribute 'file_type' can include 'container_runtime_t', which is forbidden",
+			},
+		},
+		{
+			name:   "alias of a forbidden type",
+			policy: `(typealias secrets) (typealiasactual secrets shadow_t)`,
+			want:   []string{"line 1: aliasing 'shadow_t' is forbidden"},
+		},
+		{
+			name:   "forbidden type passed to a macro",
+			policy: `(call read_files (process shadow_t))`,
+			want:   []string{"line 1: passing 'shadow_t' to a macro is forbidden"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.constraint
+			if c == nil {
+				c = constraint
+			}
+			got, err := CheckConstraint(tt.policy, c, tt.snapshot)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got violations %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+// Package lint reviews CIL policies for rules that are valid, but overly
+// broad or dangerous, such as allowing access to every file type or
+// loading kernel modules.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/cil"
+)
+
+// Finding is a rule of a policy that a lint rule objects to.
+type Finding struct {
+	// The name of the lint rule
+	Rule     string
+	Severity selinuxv1alpha1.LintSeverity
+	// The line of the policy the finding is about
+	Line int
+	// The type, attribute, permission or template the finding is about
+	Subject string
+	Msg     string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("line %d: %s [%s]", f.Line, f.Msg, f.Rule)
+}
+
+// Rule is a built-in lint rule.
+type Rule struct {
+	Name            string
+	DefaultSeverity selinuxv1alpha1.LintSeverity
+	check           func(stmt *cil.Node, cps *classPermissions, report reportFunc)
+}
+
+type reportFunc func(line int, subject, format string, args ...interface{})
+
+var (
+	broadAttributes = []string{"file_type", "domain"}
+	// The permissions that allow executing writable memory, and the
+	// classes they belong to
+	execPermissions = map[string]string{
+		"execmem":   "process",
+		"execstack": "process",
+		"execheap":  "process",
+		"execmod":   "file",
+	}
+	sensitiveTypes = []string{"shadow_t", "etc_t"}
+	// Permissions that modify an object or its directory entries
+	writePermissions = []string{"write", "append", "create", "setattr", "unlink", "rename",
+		"link", "add_name", "remove_name", "reparent", "rmdir", "relabelfrom", "relabelto"}
+	dangerousCapabilities = []string{"sys_admin", "sys_module"}
+	runtimeTypes          = []string{"container_runtime_t"}
+	// The operators of permission expressions. "all" includes every
+	// permission, and the others can include permissions that aren't
+	// listed, e.g. (not (read)).
+	permissionOperators = []string{"all", "not", "and", "or", "xor"}
+)
+
+// Rules are the built-in lint rules.
+var Rules = []Rule{
+	{
+		Name:            "broad-attribute",
+		DefaultSeverity: selinuxv1alpha1.LintSeverityWarning,
+		check: func(stmt *cil.Node, cps *classPermissions, report reportFunc) {
+			args, ok := allowArgs(stmt)
+			if !ok {
+				return
+			}
+			for _, attr := range broadAttributes {
+				if contains(atoms(args[1]), attr) {
+					report(stmt.Line, attr, "allows access to every type with the '%s' attribute", attr)
+				}
+			}
+		},
+	},
+	{
+		Name:            "exec-memory",
+		DefaultSeverity: selinuxv1alpha1.LintSeverityWarning,
+		check: func(stmt *cil.Node, cps *classPermissions, report reportFunc) {
+			args, ok := allowArgs(stmt)
+			if !ok {
+				return
+			}
+			for _, cp := range classPerms(args[2], cps) {
+				for perm, class := range execPermissions {
+					if cp.class == class && cp.has(perm) {
+						report(stmt.Line, perm, "allows '%s', which lets the process execute writable memory", perm)
+					}
+				}
+			}
+		},
+	},
+	{
+		Name:            "sensitive-write",
+		DefaultSeverity: selinuxv1alpha1.LintSeverityWarning,
+		check: func(stmt *cil.Node, cps *classPermissions, report reportFunc) {
+			args, ok := allowArgs(stmt)
+			if !ok {
+				return
+			}
+			for _, target := range sensitiveTypes {
+				if !contains(atoms(args[1]), target) {
+					continue
+				}
+				for _, cp := range classPerms(args[2], cps) {
+					for _, perm := range writePermissions {
+						if cp.has(perm) {
+							report(stmt.Line, target, "allows modifying '%s'", target)
+							return
+						}
+					}
+				}
+			}
+		},
+	},
+	{
+		Name:            "dangerous-capability",
+		DefaultSeverity: selinuxv1alpha1.LintSeverityWarning,
+		check: func(stmt *cil.Node, cps *classPermissions, report reportFunc) {
+			args, ok := allowArgs(stmt)
+			if !ok {
+				return
+			}
+			for _, cp := range classPerms(args[2], cps) {
+				if cp.class != "capability" && cp.class != "cap_userns" {
+					continue
+				}
+				for _, capability := range dangerousCapabilities {
+					if cp.has(capability) {
+						report(stmt.Line, capability, "allows the '%s' capability", capability)
+					}
+				}
+			}
+		},
+	},
+	{
+		Name:            "container-runtime",
+		DefaultSeverity: selinuxv1alpha1.LintSeverityWarning,
+		check: func(stmt *cil.Node, cps *classPermissions, report reportFunc) {
+			args, ok := allowArgs(stmt)
+			if !ok {
+				return
+			}
+			for _, target := range runtimeTypes {
+				if contains(atoms(args[1]), target) {
+					report(stmt.Line, target, "allows access to the container runtime '%s'", target)
+				}
+			}
+		},
+	},
+	{
+		Name:            "unconfined-template",
+		DefaultSeverity: selinuxv1alpha1.LintSeverityWarning,
+		check: func(stmt *cil.Node, cps *classPermissions, report reportFunc) {
+			args := stmt.Args()
+			if stmt.Keyword() != "blockinherit" || len(args) != 1 || args[0].IsList {
+				return
+			}
+			if template := args[0].Atom; isUnconfined(template) {
+				report(stmt.Line, template, "inherits the unconfined template '%s'", template)
+			}
+		},
+	},
+}
+
+// Lint runs the lint rules over the given policy of the given SelinuxPolicy,
+// and returns what they find. The findings are sorted by line. Syntax
+// errors are returned as errors, since the policy can't be reviewed.
+func Lint(policy, name, namespace string, settings *selinuxv1alpha1.LintSettings) ([]Finding, error) {
+	stmts, err := cil.Parse(policy)
+	if err != nil {
+		return nil, err
+	}
+	cps := collectClassPermissions(stmts)
+
+	var findings []Finding
+	for _, rule := range Rules {
+		severity := rule.DefaultSeverity
+		if s, ok := settings.Severities[rule.Name]; ok {
+			severity = s
+		}
+		if severity == selinuxv1alpha1.LintSeverityIgnore {
+			continue
+		}
+		report := func(line int, subject, format string, args ...interface{}) {
+			if isException(settings, rule.Name, name, namespace, subject) {
+				return
+			}
+			findings = append(findings, Finding{
+				Rule:     rule.Name,
+				Severity: severity,
+				Line:     line,
+				Subject:  subject,
+				Msg:      fmt.Sprintf(format, args...),
+			})
+		}
+		walk(stmts, func(stmt *cil.Node) {
+			rule.check(stmt, cps, report)
+		})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Msg < findings[j].Msg
+	})
+	return findings, nil
+}
+
+// Split separates the findings that need to be fixed from the ones that
+// are only warnings.
+func Split(findings []Finding) (errs []string, warnings []string) {
+	for _, finding := range findings {
+		if finding.Severity == selinuxv1alpha1.LintSeverityError {
+			errs = append(errs, finding.String())
+		} else {
+			warnings = append(warnings, finding.String())
+		}
+	}
+	return errs, warnings
+}
+
+func isException(settings *selinuxv1alpha1.LintSettings, rule, name, namespace, subject string) bool {
+	for _, exception := range settings.Exceptions {
+		if exception.Rule == rule &&
+			(exception.Namespace == "" || exception.Namespace == namespace) &&
+			(exception.Policy == "" || exception.Policy == name) &&
+			(exception.Subject == "" || exception.Subject == subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls fn for every statement, including the ones nested in blocks,
+// optionals and conditionals.
+func walk(stmts []*cil.Node, fn func(stmt *cil.Node)) {
+	for _, stmt := range stmts {
+		if !stmt.IsList {
+			continue
+		}
+		fn(stmt)
+		walk(stmt.Children, fn)
+	}
+}
+
+// classPermissions holds the named class permission sets and the class
+// mappings of a policy, so they can be resolved where they're used.
+type classPermissions struct {
+	sets map[string][]*cil.Node
+	// The class permissions that each permission of a classmap maps to
+	mappings map[string]map[string][]*cil.Node
+}
+
+// collectClassPermissions collects the named class permission sets and
+// the class mappings of a policy.
+func collectClassPermissions(stmts []*cil.Node) *classPermissions {
+	cps := &classPermissions{
+		sets:     map[string][]*cil.Node{},
+		mappings: map[string]map[string][]*cil.Node{},
+	}
+	walk(stmts, func(stmt *cil.Node) {
+		args := stmt.Args()
+		switch stmt.Keyword() {
+		case "classpermissionset":
+			if len(args) == 2 && !args[0].IsList {
+				cps.sets[args[0].Atom] = append(cps.sets[args[0].Atom], args[1])
+			}
+		case "classmapping":
+			if len(args) == 3 && !args[0].IsList && !args[1].IsList {
+				classmap, perm := args[0].Atom, args[1].Atom
+				if cps.mappings[classmap] == nil {
+					cps.mappings[classmap] = map[string][]*cil.Node{}
+				}
+				cps.mappings[classmap][perm] = append(cps.mappings[classmap][perm], args[2])
+			}
+		}
+	})
+	return cps
+}
+
+// allowArgs returns the source, target and class permissions of an allow
+// rule.
+func allowArgs(stmt *cil.Node) ([]*cil.Node, bool) {
+	args := stmt.Args()
+	if stmt.Keyword() != "allow" || len(args) != 3 {
+		return nil, false
+	}
+	return args, true
+}
+
+// atoms returns the names used in a type or permission expression
+func atoms(n *cil.Node) []string {
+	if !n.IsList {
+		return []string{n.Atom}
+	}
+	var result []string
+	for _, child := range n.Children {
+		result = append(result, atoms(child)...)
+	}
+	return result
+}
+
+type classPerm struct {
+	class string
+	perms []string
+}
+
+// has returns true if the permissions can include perm. Expressions
+// with operators are assumed to include every permission of the class.
+func (cp classPerm) has(perm string) bool {
+	if contains(cp.perms, perm) {
+		return true
+	}
+	for _, op := range permissionOperators {
+		if contains(cp.perms, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// classPerms returns the classes and permissions of an anonymous or named
+// class permission. Classmaps are resolved into the classes and
+// permissions they map to.
+func classPerms(n *cil.Node, cps *classPermissions) []classPerm {
+	return resolveClassPerms(n, cps, map[string]bool{})
+}
+
+// resolveClassPerms resolves named class permissions and classmaps, which
+// can be built from other named ones. seen holds what was already
+// resolved, so cycles end.
+func resolveClassPerms(n *cil.Node, cps *classPermissions, seen map[string]bool) []classPerm {
+	if !n.IsList {
+		if seen[n.Atom] {
+			return nil
+		}
+		seen[n.Atom] = true
+		var result []classPerm
+		for _, set := range cps.sets[n.Atom] {
+			result = append(result, resolveClassPerms(set, cps, seen)...)
+		}
+		return result
+	}
+	if len(n.Children) != 2 || n.Children[0].IsList {
+		return nil
+	}
+	cp := classPerm{class: n.Children[0].Atom, perms: atoms(n.Children[1])}
+	mapping, ok := cps.mappings[cp.class]
+	if !ok {
+		return []classPerm{cp}
+	}
+	perms := make([]string, 0, len(mapping))
+	for perm := range mapping {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	var result []classPerm
+	for _, perm := range perms {
+		// Class and permission names can't contain spaces, so this
+		// can't clash with the name of a set.
+		key := cp.class + " " + perm
+		if !cp.has(perm) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		for _, target := range mapping[perm] {
+			result = append(result, resolveClassPerms(target, cps, seen)...)
+		}
+	}
+	return result
+}
+
+func isUnconfined(template string) bool {
+	return strings.Contains(template, "unconfined")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		settings selinuxv1alpha1.LintSettings
+		want     []string
+	}{
+		{
+			name:   "clean policy",
+			policy: `(blockinherit container) (allow process var_spool_t (file (read open getattr)))`,
+		},
+		{
+			name:   "broad attribute",
+			policy: `(allow process file_type (file (read)))`,
+			want:   []string{"line 1: allows access to every type with the 'file_type' attribute [broad-attribute]"},
+		},
+		{
+			name:   "broad attribute in an expression",
+			policy: `(allow process (and file_type (not shadow_t)) (file (read)))`,
+			want:   []string{"line 1: allows access to every type with the 'file_type' attribute [broad-attribute]"},
+		},
+		{
+			name:   "exec memory",
+			policy: `(allow process self (process (execmem)))`,
+			want:   []string{"line 1: allows 'execmem', which lets the process execute writable memory [exec-memory]"},
+		},
+		{
+			name:   "exec memory of another class",
+			policy: `(allow process self (file (execmem)))`,
+		},
+		{
+			name:   "all permissions",
+			policy: `(allow process self (file (all)))`,
+			want:   []string{"line 1: allows 'execmod', which lets the process execute writable memory [exec-memory]"},
+		},
+		{
+			name:   "complement of the permissions",
+			policy: `(allow process self (file (not (read))))`,
+			want:   []string{"line 1: allows 'execmod', which lets the process execute writable memory [exec-memory]"},
+		},
+		{
+			name: "permission expressions",
+			policy: `(allow process self (file (and (read) (write))))
+(allow process self (file (or (read) (write))))
+(allow process self (file (xor (read) (write))))`,
+			want: []string{
+				"line 1: allows 'execmod', which lets the process execute writable memory [exec-memory]",
+				"line 2: allows 'execmod', which lets the process execute writable memory [exec-memory]",
+				"line 3: allows 'execmod', which lets the process execute writable memory [exec-memory]",
+			},
+		},
+		{
+			name: "named class permission",
+			policy: `(classpermission caps)
+(classpermissionset caps (capability (sys_admin)))
+(allow process self caps)`,
+			want: []string{"line 3: allows the 'sys_admin' capability [dangerous-capability]"},
+		},
+		{
+			name: "nested class permissions",
+			policy: `(classpermission inner)
+(classpermissionset inner (capability (sys_module)))
+(classpermission outer)
+(classpermissionset outer inner)
+(allow process self outer)`,
+			want: []string{"line 5: allows the 'sys_module' capability [dangerous-capability]"},
+		},
+		{
+			name: "cyclic class permissions",
+			policy: `(classpermission a)
+(classpermission b)
+(classpermissionset a b)
+(classpermissionset b a)
+(classpermissionset b (cap_userns (sys_admin)))
+(allow process self a)`,
+			want: []string{"line 6: allows the 'sys_admin' capability [dangerous-capability]"},
+		},
+		{
+			name:   "sensitive write",
+			policy: `(allow process shadow_t (file (read append)))`,
+			want:   []string{"line 1: allows modifying 'shadow_t' [sensitive-write]"},
+		},
+		{
+			name:   "sensitive read",
+			policy: `(allow process etc_t (file (read open)))`,
+		},
+		{
+			name: "container runtime in an optional",
+			policy: `(optional runtime
+    (allow process container_runtime_t (unix_stream_socket (connectto)))
+)`,
+			want: []string{"line 2: allows access to the container runtime 'container_runtime_t' [container-runtime]"},
+		},
+		{
+			name:   "unconfined template",
+			policy: `(blockinherit unconfined_container)`,
+			want:   []string{"line 1: inherits the unconfined template 'unconfined_container' [unconfined-template]"},
+		},
+		{
+			name:   "ignored rule",
+			policy: `(allow process self (process (execmem)))`,
+			settings: selinuxv1alpha1.LintSettings{
+				Severities: map[string]selinuxv1alpha1.LintSeverity{"exec-memory": selinuxv1alpha1.LintSeverityIgnore},
+			},
+		},
+		{
+			name:   "exception for the policy",
+			policy: `(allow process file_type (file (read))) (allow process self (process (execmem)))`,
+			settings: selinuxv1alpha1.LintSettings{
+				Exceptions: []selinuxv1alpha1.LintException{{Rule: "broad-attribute", Policy: "app", Subject: "file_type"}},
+			},
+			want: []string{"line 1: allows 'execmem', which lets the process execute writable memory [exec-memory]"},
+		},
+		{
+			name:   "exception for another namespace",
+			policy: `(allow process file_type (file (read)))`,
+			settings: selinuxv1alpha1.LintSettings{
+				Exceptions: []selinuxv1alpha1.LintException{{Rule: "broad-attribute", Namespace: "other"}},
+			},
+			want: []string{"line 1: allows access to every type with the 'file_type' attribute [broad-attribute]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := Lint(tt.policy, "app", "default", &tt.settings)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got findings %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintSyntaxError(t *testing.T) {
+	if _, err := Lint("(allow process self", "app", "default", &selinuxv1alpha1.LintSettings{}); err == nil {
+		t.Error("expected an error for an unbalanced policy")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	findings := []Finding{
+		{Rule: "exec-memory", Severity: selinuxv1alpha1.LintSeverityError, Line: 1, Msg: "bad"},
+		{Rule: "broad-attribute", Severity: selinuxv1alpha1.LintSeverityWarning, Line: 2, Msg: "meh"},
+	}
+	errs, warnings := Split(findings)
+	if want := []string{"line 1: bad [exec-memory]"}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("got errors %q, want %q", errs, want)
+	}
+	if want := []string{"line 2: meh [broad-attribute]"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %q, want %q", warnings, want)
+	}
+}
@@ -0,0 +1,141 @@
+// Package operatorconfig keeps track of the operator settings, which are
+// read from the SelinuxOperatorConfig object at runtime.
+package operatorconfig
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/templates"
+)
+
+// Name is the name of the SelinuxOperatorConfig object holding the
+// settings. Any other object is ignored.
+const Name = "cluster"
+
+var (
+	mu          sync.RWMutex
+	current     = Defaults()
+	subscribers []chan struct{}
+)
+
+// Defaults returns the settings used when they're not set in the
+// SelinuxOperatorConfig object.
+func Defaults() *selinuxv1alpha1.SelinuxOperatorConfigSpec {
+	return &selinuxv1alpha1.SelinuxOperatorConfigSpec{
+		InstallerImage: "quay.io/jaosorior/udica",
+		InstallerTolerations: []corev1.Toleration{
+			{
+				Key:      "node-role.kubernetes.io/master",
+				Operator: "Exists",
+				Effect:   "NoSchedule",
+			},
+		},
+		TemplateVersion:     templates.DefaultVersion,
+		FallbackNamespace:   "openshift-selinux-operator",
+		WebhookPort:         8443,
+		WebhookCertDir:      "/tmp/k8s-webhook-server/serving-certs",
+		WebhookCertificates: selinuxv1alpha1.WebhookCertificatesOperator,
+		MetricsPort:         8383,
+		OperatorMetricsPort: 8686,
+		PodAdmission: selinuxv1alpha1.PodAdmissionSettings{
+			UninstalledPolicy: selinuxv1alpha1.UninstalledPolicyDeny,
+			Availability:      selinuxv1alpha1.PolicyAvailabilityNodeAffinity,
+		},
+	}
+}
+
+// Get returns the current settings.
+func Get() *selinuxv1alpha1.SelinuxOperatorConfigSpec {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.DeepCopy()
+}
+
+// Fetch reads the SelinuxOperatorConfig object through the given client
+// and makes it the current settings. If the object doesn't exist, the
+// defaults are used.
+func Fetch(ctx context.Context, c client.Reader) (*selinuxv1alpha1.SelinuxOperatorConfigSpec, error) {
+	instance := &selinuxv1alpha1.SelinuxOperatorConfig{}
+	err := c.Get(ctx, types.NamespacedName{Name: Name}, instance)
+	if errors.IsNotFound(err) {
+		set(Defaults())
+	} else if err != nil {
+		return nil, err
+	} else {
+		set(withDefaults(&instance.Spec))
+	}
+	return Get(), nil
+}
+
+// Subscribe returns a channel that receives a value whenever the settings
+// change.
+func Subscribe() <-chan struct{} {
+	mu.Lock()
+	defer mu.Unlock()
+	ch := make(chan struct{}, 1)
+	subscribers = append(subscribers, ch)
+	return ch
+}
+
+func set(spec *selinuxv1alpha1.SelinuxOperatorConfigSpec) {
+	mu.Lock()
+	defer mu.Unlock()
+	if reflect.DeepEqual(current, spec) {
+		return
+	}
+	current = spec
+	for _, ch := range subscribers {
+		// Subscribers only need to know that something changed
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func withDefaults(in *selinuxv1alpha1.SelinuxOperatorConfigSpec) *selinuxv1alpha1.SelinuxOperatorConfigSpec {
+	spec := in.DeepCopy()
+	defaults := Defaults()
+	if spec.InstallerImage == "" {
+		spec.InstallerImage = defaults.InstallerImage
+	}
+	if spec.InstallerTolerations == nil {
+		spec.InstallerTolerations = defaults.InstallerTolerations
+	}
+	if spec.TemplateVersion == "" {
+		spec.TemplateVersion = defaults.TemplateVersion
+	}
+	if spec.FallbackNamespace == "" {
+		spec.FallbackNamespace = defaults.FallbackNamespace
+	}
+	if spec.WebhookPort == 0 {
+		spec.WebhookPort = defaults.WebhookPort
+	}
+	if spec.WebhookCertDir == "" {
+		spec.WebhookCertDir = defaults.WebhookCertDir
+	}
+	if spec.WebhookCertificates == "" {
+		spec.WebhookCertificates = defaults.WebhookCertificates
+	}
+	if spec.MetricsPort == 0 {
+		spec.MetricsPort = defaults.MetricsPort
+	}
+	if spec.OperatorMetricsPort == 0 {
+		spec.OperatorMetricsPort = defaults.OperatorMetricsPort
+	}
+	if spec.PodAdmission.UninstalledPolicy == "" {
+		spec.PodAdmission.UninstalledPolicy = defaults.PodAdmission.UninstalledPolicy
+	}
+	if spec.PodAdmission.Availability == "" {
+		spec.PodAdmission.Availability = defaults.PodAdmission.Availability
+	}
+	return spec
+}
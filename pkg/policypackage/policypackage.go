@@ -0,0 +1,152 @@
+// Package policypackage reads the metadata of binary SELinux policy
+// packages (.pp files), as produced by semodule_package or shipped by
+// vendors.
+package policypackage
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	// Magic number at the start of a module package
+	modulePackageMagic uint32 = 0xf97cff8f
+	// Magic number at the start of a module policydb
+	moduleMagic uint32 = 0xf97cff8d
+	// Identification string of a module policydb
+	moduleString = "SE Linux Module"
+	// Policy type of a non-base module
+	policyTypeModule uint32 = 2
+
+	// Upper bound for any string length we read, so a corrupt
+	// package can't make us allocate arbitrary amounts of memory.
+	maxStringLen = 4096
+	// Upper bound for the decompressed size of a package
+	maxPackageSize = 64 << 20
+)
+
+// ModuleName returns the name of the module contained in the given
+// policy package. Packages compressed with bzip2 are also accepted.
+func ModuleName(data []byte) (string, error) {
+	data, err := decompress(data)
+	if err != nil {
+		return "", err
+	}
+
+	r := &reader{data: data}
+	magic, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if magic != modulePackageMagic {
+		return "", fmt.Errorf("not a policy package: wrong magic number %#x", magic)
+	}
+	// Skip the package format version
+	if _, err := r.uint32(); err != nil {
+		return "", err
+	}
+	nsec, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if nsec == 0 {
+		return "", fmt.Errorf("policy package contains no sections")
+	}
+	// The policy itself is always the first section
+	offset, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if int(offset) >= len(data) {
+		return "", fmt.Errorf("policy section offset %d is out of bounds", offset)
+	}
+
+	r = &reader{data: data[offset:]}
+	magic, err = r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if magic != moduleMagic {
+		return "", fmt.Errorf("policy package does not contain a module: wrong magic number %#x", magic)
+	}
+	ident, err := r.string()
+	if err != nil {
+		return "", err
+	}
+	if ident != moduleString {
+		return "", fmt.Errorf("policy package does not contain a module: unexpected identifier '%s'", ident)
+	}
+	policyType, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if policyType != policyTypeModule {
+		return "", fmt.Errorf("base policy packages are not supported")
+	}
+	// Skip the policy version, config, symbol and ocontext counts
+	for i := 0; i < 4; i++ {
+		if _, err := r.uint32(); err != nil {
+			return "", err
+		}
+	}
+	return r.string()
+}
+
+func decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte("BZh")) {
+		return data, nil
+	}
+	decompressed, err := ioutil.ReadAll(
+		&limitedReader{r: bzip2.NewReader(bytes.NewReader(data)), n: maxPackageSize})
+	if err != nil {
+		return nil, fmt.Errorf("decompressing policy package: %w", err)
+	}
+	return decompressed, nil
+}
+
+// reader reads little-endian values from a policy package
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) uint32() (uint32, error) {
+	if r.pos+4 > len(r.data) {
+		return 0, fmt.Errorf("policy package is truncated")
+	}
+	val := binary.LittleEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return val, nil
+}
+
+func (r *reader) string() (string, error) {
+	length, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+	if length > maxStringLen || r.pos+int(length) > len(r.data) {
+		return "", fmt.Errorf("policy package is truncated")
+	}
+	val := string(r.data[r.pos : r.pos+int(length)])
+	r.pos += int(length)
+	return val, nil
+}
+
+// limitedReader fails once more than n bytes have been read
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, fmt.Errorf("policy package exceeds %d bytes", maxPackageSize)
+	}
+	return n, err
+}
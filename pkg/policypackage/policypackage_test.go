@@ -0,0 +1,178 @@
+package policypackage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// modulePackage builds a policy package the way semodule_package lays it
+// out, with the module as its only section.
+type modulePackage struct {
+	packageMagic uint32
+	sections     uint32
+	offset       uint32
+	moduleMagic  uint32
+	ident        string
+	policyType   uint32
+	name         string
+}
+
+func validPackage() modulePackage {
+	return modulePackage{
+		packageMagic: modulePackageMagic,
+		sections:     1,
+		offset:       16,
+		moduleMagic:  moduleMagic,
+		ident:        moduleString,
+		policyType:   policyTypeModule,
+		name:         "app_default",
+	}
+}
+
+func (p modulePackage) bytes() []byte {
+	buf := &bytes.Buffer{}
+	write := func(v uint32) {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+	writeString := func(s string) {
+		write(uint32(len(s)))
+		buf.WriteString(s)
+	}
+	write(p.packageMagic)
+	// The package format version
+	write(1)
+	write(p.sections)
+	write(p.offset)
+	write(p.moduleMagic)
+	writeString(p.ident)
+	write(p.policyType)
+	// The policy version, config, symbol and ocontext counts
+	for i := uint32(0); i < 4; i++ {
+		write(i)
+	}
+	writeString(p.name)
+	return buf.Bytes()
+}
+
+// The package returned by validPackage, compressed with bzip2
+const compressedPackage = "425a68393141592653597defe4cb000022f7c0fc08c000400002060800a725c64400028020a0005454d3000260027a0d53f489b48d0068da9a02202e7ba105502c1e91a9010cab9e21ec67b3539c2750d4934629d2809fbe0803a2ee48a70a120fbdfc9960"
+
+func TestModuleName(t *testing.T) {
+	valid := validPackage().bytes()
+	compressed, err := hex.DecodeString(compressedPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr string
+	}{
+		{
+			name: "module package",
+			data: valid,
+			want: "app_default",
+		},
+		{
+			name: "bzip2-compressed package",
+			data: compressed,
+			want: "app_default",
+		},
+		{
+			name:    "empty",
+			data:    nil,
+			wantErr: "truncated",
+		},
+		{
+			name:    "truncated module name",
+			data:    valid[:len(valid)-3],
+			wantErr: "truncated",
+		},
+		{
+			name:    "not a package",
+			data:    []byte("(block app_default (type process))"),
+			wantErr: "not a policy package",
+		},
+		{
+			name: "no sections",
+			data: func() []byte {
+				p := validPackage()
+				p.sections = 0
+				return p.bytes()
+			}(),
+			wantErr: "no sections",
+		},
+		{
+			name: "section out of bounds",
+			data: func() []byte {
+				p := validPackage()
+				p.offset = 1 << 20
+				return p.bytes()
+			}(),
+			wantErr: "out of bounds",
+		},
+		{
+			name: "not a module",
+			data: func() []byte {
+				p := validPackage()
+				p.moduleMagic = 0xdeadbeef
+				return p.bytes()
+			}(),
+			wantErr: "does not contain a module",
+		},
+		{
+			name: "unexpected identifier",
+			data: func() []byte {
+				p := validPackage()
+				p.ident = "SE Linux"
+				return p.bytes()
+			}(),
+			wantErr: "unexpected identifier",
+		},
+		{
+			name: "base policy",
+			data: func() []byte {
+				p := validPackage()
+				p.policyType = 1
+				return p.bytes()
+			}(),
+			wantErr: "base policy packages are not supported",
+		},
+		{
+			name: "oversized module name",
+			data: func() []byte {
+				p := validPackage()
+				p.name = strings.Repeat("a", maxStringLen+1)
+				return p.bytes()
+			}(),
+			wantErr: "truncated",
+		},
+		{
+			name:    "corrupt bzip2 data",
+			data:    []byte("BZh9 definitely not bzip2"),
+			wantErr: "decompressing policy package",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ModuleName(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got module %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
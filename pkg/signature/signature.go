@@ -0,0 +1,90 @@
+// Package signature verifies the ed25519 signatures of policies.
+//
+// What gets signed is the payload built by Payload, which binds the content
+// of the policy to its namespace, name, mode and template version, so a
+// signed policy can't be copied elsewhere or made permissive. A policy is
+// signed with the private key that matches one of the trusted public keys,
+// e.g. with:
+//
+//	printf 'namespace: %s\nname: %s\nmode: %s\npermissiveUntil: %s\ntemplateVersion: %s\nsha256: %s\n' \
+//	    my-namespace my-policy enforcing "" "" "$(sha256sum < policy.cil | cut -d' ' -f1)" > payload
+//	openssl pkeyutl -sign -inkey key.pem -rawin -in payload | base64 -w0
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+)
+
+var (
+	// ErrUnsigned is returned for content without a signature
+	ErrUnsigned = errors.New("the policy isn't signed")
+	// ErrUntrusted is returned when none of the trusted keys made the
+	// signature, or the content was changed after it was signed.
+	ErrUntrusted = errors.New("the policy's signature doesn't match any of the trusted keys")
+)
+
+// Payload returns what the signature of the given policy is made over. The
+// content is the policy field, or the precompiled package that binaryPolicy
+// references. The mode is always spelled out, and permissiveUntil is only
+// set for permissive policies, in RFC 3339 format and in UTC.
+func Payload(sp *selinuxv1alpha1.SelinuxPolicy, content []byte) []byte {
+	mode := selinuxv1alpha1.PolicyModeEnforcing
+	permissiveUntil := ""
+	if sp.Spec.Mode == selinuxv1alpha1.PolicyModePermissive {
+		mode = selinuxv1alpha1.PolicyModePermissive
+		if sp.Spec.PermissiveUntil != nil {
+			permissiveUntil = sp.Spec.PermissiveUntil.UTC().Format(time.RFC3339)
+		}
+	}
+	return []byte(fmt.Sprintf("namespace: %s\nname: %s\nmode: %s\npermissiveUntil: %s\ntemplateVersion: %s\nsha256: %x\n",
+		sp.Namespace, sp.Name, mode, permissiveUntil, sp.Spec.TemplateVersion, sha256.Sum256(content)))
+}
+
+// ParsePublicKey parses a PEM-encoded ed25519 public key.
+func ParsePublicKey(data string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("expected a PEM-encoded public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ed25519 public key, got %T", key)
+	}
+	return edKey, nil
+}
+
+// Verify checks that the base64-encoded signature of the payload was made
+// by one of the trusted keys. Keys that can't be parsed are skipped, so a
+// single broken key doesn't stop every policy from being installed.
+func Verify(payload []byte, signature string, trustedKeys []string) error {
+	if signature == "" {
+		return ErrUnsigned
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("the policy's signature isn't valid base64: %w", err)
+	}
+	for _, data := range trustedKeys {
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(key, payload, sig) {
+			return nil
+		}
+	}
+	return ErrUntrusted
+}
@@ -0,0 +1,179 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+)
+
+func newKey(t *testing.T, seed byte) (ed25519.PrivateKey, string) {
+	t.Helper()
+	priv := ed25519.NewKeyFromSeed([]byte(strings.Repeat(string(seed), ed25519.SeedSize)))
+	return priv, encodePublicKey(t, priv.Public())
+}
+
+func encodePublicKey(t *testing.T, pub interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func sign(priv ed25519.PrivateKey, payload []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, payload))
+}
+
+func TestPayload(t *testing.T) {
+	until := metav1.NewTime(time.Date(2030, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)))
+	tests := []struct {
+		name string
+		spec selinuxv1alpha1.SelinuxPolicySpec
+		want string
+	}{
+		{
+			name: "mode defaults to enforcing",
+			spec: selinuxv1alpha1.SelinuxPolicySpec{TemplateVersion: "v0.2.1"},
+			want: "namespace: default\nname: app\nmode: enforcing\npermissiveUntil: \ntemplateVersion: v0.2.1\n",
+		},
+		{
+			name: "deadline of an enforcing policy",
+			spec: selinuxv1alpha1.SelinuxPolicySpec{Mode: selinuxv1alpha1.PolicyModeEnforcing, PermissiveUntil: &until},
+			want: "namespace: default\nname: app\nmode: enforcing\npermissiveUntil: \ntemplateVersion: \n",
+		},
+		{
+			name: "permissive policy",
+			spec: selinuxv1alpha1.SelinuxPolicySpec{Mode: selinuxv1alpha1.PolicyModePermissive},
+			want: "namespace: default\nname: app\nmode: permissive\npermissiveUntil: \ntemplateVersion: \n",
+		},
+		{
+			name: "permissive policy with a deadline",
+			spec: selinuxv1alpha1.SelinuxPolicySpec{Mode: selinuxv1alpha1.PolicyModePermissive, PermissiveUntil: &until},
+			want: "namespace: default\nname: app\nmode: permissive\npermissiveUntil: 2030-01-02T02:04:05Z\ntemplateVersion: \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := &selinuxv1alpha1.SelinuxPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+			got := string(Payload(sp, []byte("")))
+			// The SHA-256 checksum of empty content
+			want := tt.want + "sha256: e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855\n"
+			if got != want {
+				t.Errorf("got payload %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	priv, pub := newKey(t, 1)
+	_, otherPub := newKey(t, 2)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPub := encodePublicKey(t, ecKey.Public())
+
+	payload := []byte("namespace: default\nname: app\n")
+	signature := sign(priv, payload)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		keys      []string
+		wantErr   error
+	}{
+		{
+			name:      "trusted key",
+			payload:   payload,
+			signature: signature,
+			keys:      []string{otherPub, pub},
+		},
+		{
+			name:      "broken and foreign keys are skipped",
+			payload:   payload,
+			signature: signature,
+			keys:      []string{"not a key", ecPub, pub},
+		},
+		{
+			name:    "unsigned",
+			payload: payload,
+			keys:    []string{pub},
+			wantErr: ErrUnsigned,
+		},
+		{
+			name:      "untrusted key",
+			payload:   payload,
+			signature: signature,
+			keys:      []string{otherPub},
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "no trusted keys",
+			payload:   payload,
+			signature: signature,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "changed payload",
+			payload:   []byte("namespace: other\nname: app\n"),
+			signature: signature,
+			keys:      []string{pub},
+			wantErr:   ErrUntrusted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.payload, tt.signature, tt.keys); err != tt.wantErr {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Verify(payload, "not base64!", []string{pub}); err == nil || !strings.Contains(err.Error(), "base64") {
+		t.Errorf("got error %v for an invalid signature", err)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	priv, pub := newKey(t, 1)
+	key, err := ParsePublicKey(pub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(key, priv.Public().(ed25519.PublicKey)) {
+		t.Error("parsed a different key")
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"not PEM":     "ssh-ed25519 AAAA",
+		"private key": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}})),
+		"invalid DER": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}})),
+		"not ed25519": encodePublicKey(t, ecKey.Public()),
+	} {
+		if _, err := ParsePublicKey(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
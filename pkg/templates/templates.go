@@ -0,0 +1,73 @@
+// Package templates contains the udica base templates that the operator
+// ships and installs on the nodes. SELinux policies build on these
+// templates through CIL's blockinherit statement.
+//
+// The templates are versioned as a whole, since their block names are
+// shared among versions: only one version of the library can be
+// installed on a node at a time.
+package templates
+
+import (
+	"sort"
+)
+
+// DefaultVersion is the version of the template library that gets
+// installed unless configured otherwise.
+const DefaultVersion = "v0.2.1"
+
+// Template is a single udica base template.
+type Template struct {
+	// The name of the SELinux module that holds the template
+	Module string
+	// The name of the CIL block that policies inherit from
+	Block string
+	// The CIL source of the template
+	Policy string
+}
+
+// Library is a versioned set of base templates.
+type Library struct {
+	Version   string
+	Templates []Template
+}
+
+var libraries = map[string]*Library{}
+
+func register(lib *Library) {
+	libraries[lib.Version] = lib
+}
+
+// Get returns the template library with the given version.
+func Get(version string) (*Library, bool) {
+	lib, ok := libraries[version]
+	return lib, ok
+}
+
+// Versions returns the versions of all the bundled template libraries.
+func Versions() []string {
+	versions := make([]string, 0, len(libraries))
+	for version := range libraries {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Blocks returns the names of the blocks that the library provides.
+func (l *Library) Blocks() []string {
+	blocks := make([]string, 0, len(l.Templates))
+	for _, t := range l.Templates {
+		blocks = append(blocks, t.Block)
+	}
+	return blocks
+}
+
+// Lookup returns the template that provides the given block.
+func (l *Library) Lookup(block string) (*Template, bool) {
+	for i := range l.Templates {
+		if l.Templates[i].Block == block {
+			return &l.Templates[i], true
+		}
+	}
+	return nil, false
+}
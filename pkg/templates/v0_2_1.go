@@ -0,0 +1,112 @@
+package templates
+
+func init() {
+	register(&Library{
+		Version: "v0.2.1",
+		Templates: []Template{
+			{
+				Module: "base_container",
+				Block:  "container",
+				Policy: `(block container
+    (type process)
+    (type socket)
+    (roletype system_r process)
+    (typeattributeset domain (process ))
+    (typeattributeset container_domain (process ))
+    (typeattributeset svirt_sandbox_domain (process ))
+    (typeattributeset mcs_constrained_type (process ))
+    (typeattributeset file_type (socket ))
+    (allow process socket (sock_file (create open getattr setattr read write rename link unlink ioctl lock append)))
+    (allow process proc_type (file (getattr open read)))
+    (allow process cpu_online_t (file (getattr open read)))
+    (allow container_runtime_t process (key (create link read search setattr view write)))
+)
+`,
+			},
+			{
+				Module: "net_container",
+				Block:  "net_container",
+				Policy: `(block net_container
+    (optional net_container_optional
+        (typeattributeset sandbox_net_domain (process))
+    )
+)
+`,
+			},
+			{
+				Module: "home_container",
+				Block:  "home_container",
+				Policy: `(block home_container
+    (optional home_container_optional
+        (allow process home_root_t (dir (getattr search open read lock ioctl)))
+        (allow process user_home_dir_t (dir (getattr search open read lock ioctl)))
+        (allow process user_home_t (dir (add_name create getattr ioctl link lock open read remove_name rename reparent rmdir search setattr unlink write)))
+        (allow process user_home_t (file (append create getattr ioctl link lock map open read rename setattr unlink write)))
+        (allow process user_home_t (lnk_file (append create getattr ioctl link lock read rename setattr unlink write)))
+    )
+)
+`,
+			},
+			{
+				Module: "log_container",
+				Block:  "log_container",
+				Policy: `(block log_container
+    (optional log_container_optional
+        (allow process logfile (dir (getattr search open read lock ioctl add_name write)))
+        (allow process logfile (file (append create getattr ioctl lock map open read write)))
+        (allow process logfile (lnk_file (getattr read)))
+    )
+)
+`,
+			},
+			{
+				Module: "config_container",
+				Block:  "config_container",
+				Policy: `(block config_container
+    (optional config_container_optional
+        (allow process etc_t (dir (getattr search open read lock ioctl)))
+        (allow process etc_t (file (getattr read open ioctl lock map)))
+        (allow process etc_t (lnk_file (getattr read)))
+    )
+)
+`,
+			},
+			{
+				Module: "tty_container",
+				Block:  "tty_container",
+				Policy: `(block tty_container
+    (optional tty_container_optional
+        (allow process container_devpts_t (chr_file (read write open getattr ioctl append lock)))
+        (allow process devpts_t (dir (getattr search open)))
+    )
+)
+`,
+			},
+			{
+				Module: "virt_container",
+				Block:  "virt_container",
+				Policy: `(block virt_container
+    (optional virt_container_optional
+        (allow process virt_var_run_t (dir (getattr search open read lock ioctl)))
+        (allow process virt_var_run_t (sock_file (getattr write open append)))
+        (allow process virtd_t (unix_stream_socket (connectto)))
+    )
+)
+`,
+			},
+			{
+				Module: "x_container",
+				Block:  "x_container",
+				Policy: `(block x_container
+    (optional x_container_optional
+        (allow process xserver_misc_device_t (chr_file (read write open getattr ioctl)))
+        (allow process user_tmp_t (dir (search getattr open read)))
+        (allow process user_tmp_t (sock_file (write getattr open)))
+        (allow process xserver_t (unix_stream_socket (connectto)))
+    )
+)
+`,
+			},
+		},
+	})
+}
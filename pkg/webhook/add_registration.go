@@ -0,0 +1,10 @@
+package webhook
+
+import (
+	"github.com/JAORMX/selinux-operator/pkg/webhook/registration"
+)
+
+func init() {
+	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
+	AddToManagerFuncs = append(AddToManagerFuncs, registration.Add)
+}
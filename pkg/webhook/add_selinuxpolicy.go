@@ -2,9 +2,13 @@ package webhook
 
 import (
 	"github.com/JAORMX/selinux-operator/pkg/webhook/namespace"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/podpolicy"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/selinuxpolicy"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/selinuxpolicyapproval"
 )
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, namespace.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, namespace.Add, selinuxpolicy.Add, selinuxpolicy.AddAuthor,
+		selinuxpolicyapproval.Add, podpolicy.Add)
 }
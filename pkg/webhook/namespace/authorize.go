@@ -0,0 +1,82 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+// useVerb is the verb that users and service accounts need on a
+// SelinuxPolicy for pods to run with it
+const useVerb = "use"
+
+// authorizeUse checks that the user creating the pod, or the pod's service
+// account, is allowed to use every policy that the pod runs with. It
+// returns why the pod is rejected, if it is.
+func (v *ValidateNamespace) authorizeUse(ctx context.Context, user authenticationv1.UserInfo, pod *corev1.Pod) (string, error) {
+	serviceAccount := pod.Spec.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	serviceAccountUser := authenticationv1.UserInfo{
+		Username: fmt.Sprintf("system:serviceaccount:%s:%s", pod.Namespace, serviceAccount),
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + pod.Namespace},
+	}
+
+	for _, name := range utils.GetPodPolicyNames(pod, pod.Namespace) {
+		allowed, err := v.canUse(ctx, user, name, pod.Namespace)
+		if err != nil {
+			return "", err
+		}
+		if !allowed {
+			allowed, err = v.canUse(ctx, serviceAccountUser, name, pod.Namespace)
+			if err != nil {
+				return "", err
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("neither user '%s' nor service account '%s' is allowed to use SelinuxPolicy '%s'",
+				user.Username, serviceAccount, name), nil
+		}
+	}
+	return "", nil
+}
+
+// canUse asks the API server whether the user can use the given policy
+func (v *ValidateNamespace) canUse(ctx context.Context, user authenticationv1.UserInfo, name, ns string) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, values := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(values)
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: ns,
+				Verb:      useVerb,
+				Group:     selinuxv1alpha1.SchemeGroupVersion.Group,
+				Version:   selinuxv1alpha1.SchemeGroupVersion.Version,
+				Resource:  "selinuxpolicies",
+				Name:      name,
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			Extra:  extra,
+			UID:    user.UID,
+		},
+	}
+	if err := v.client.Create(ctx, sar); err != nil {
+		return false, err
+	}
+	if sar.Status.EvaluationError != "" && !sar.Status.Allowed {
+		log.Info("The access review was inconclusive", "User", user.Username, "error",
+			strings.TrimSpace(sar.Status.EvaluationError))
+	}
+	return sar.Status.Allowed, nil
+}
@@ -1,33 +1,40 @@
 // This webhook validates that the pod that's being reviewed is using
 // a SELinux policy that exists and is available in the namespace that
-// that the pod is being created on.
+// that the pod is being created on. Pods created with a policy that isn't
+// installed are rejected, or admitted with a warning, depending on the
+// operator's settings. If the operator authorizes the use of policies,
+// pods are rejected unless the user creating them, or their service
+// account, is allowed to use their policy. The pod templates of workloads
+// get the same checks on the policies they reference, so they're rejected
+// before any of their pods are.
 
 package namespace
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/go-logr/logr"
-	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	"sigs.k8s.io/controller-runtime/pkg/webhook"
-	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/podpolicy"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/review"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/server"
 )
 
 const (
-	webhookPort = 8443
-	webhookHost = "0.0.0.0"
 	webhookPath = "/validate-selinuxpolicy-namespace"
 )
 
@@ -36,109 +43,169 @@ var log = logf.Log.WithName("webhook_namespace")
 // ValidateNamespace validates that the given pod's selinux policy exists in the namespace
 type ValidateNamespace struct {
 	client client.Client
-	codecs serializer.CodecFactory
 }
 
 // Add creates a new SelinuxPolicy Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	// Create a webhook server.
-	//hookServer := mgr.GetWebhookServer()
-	hookServer := &webhook.Server{
-		Host:    webhookHost,
-		Port:    webhookPort,
-		CertDir: "/tmp/k8s-webhook-server/serving-certs",
-	}
-	if err := mgr.Add(hookServer); err != nil {
-		return err
-	}
-
 	validator := &ValidateNamespace{
 		client: mgr.GetClient(),
-
-		codecs: serializer.NewCodecFactory(mgr.GetScheme()),
-	}
-
-	validatingHook := &webhook.Admission{
-		Handler: admission.HandlerFunc(func(ctx context.Context, req webhook.AdmissionRequest) webhook.AdmissionResponse {
-			return validator.Handle(ctx, req)
-		}),
 	}
 
 	// Register the webhooks in the server.
-	hookServer.Register(webhookPath, validatingHook)
-
-	return nil
+	return server.Register(mgr, webhookPath, &review.Webhook{Handler: validator.Handle})
 }
 
 // Handle handles requests for AdmissionRequests
-func (v *ValidateNamespace) Handle(ctx context.Context, req webhook.AdmissionRequest) webhook.AdmissionResponse {
+func (v *ValidateNamespace) Handle(ctx context.Context, req *admissionv1.AdmissionRequest) review.Response {
 	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 
 	podResource := metav1.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
 	if req.Resource != podResource {
-		reqLogger.Info("Got a request for the wrong resource.")
-		return webhook.Errored(500, fmt.Errorf("got a request for the wrong resource"))
+		return v.validatePodTemplate(ctx, reqLogger, req)
 	}
 
-	raw := req.Object.Raw
 	pod := corev1.Pod{}
-	deserializer := v.codecs.UniversalDeserializer()
-	if _, _, err := deserializer.Decode(raw, nil, &pod); err != nil {
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
 		reqLogger.Info("ERROR: Unable to decode pod")
-		return webhook.Errored(500, fmt.Errorf("got a request but couldn't decode the pod"))
+		return review.Errored(400, fmt.Errorf("got a request but couldn't decode the pod"))
 	}
+	// The namespace isn't always set in the object
+	pod.Namespace = req.Namespace
 
-	if req.Operation == admissionv1beta1.Create || req.Operation == admissionv1beta1.Update {
-		return v.validateSelinuxNamespace(ctx, reqLogger, &pod)
+	if req.Operation == admissionv1.Create || req.Operation == admissionv1.Update {
+		// The SELinux options of a pod can't change once it's created,
+		// so the policy's state only matters when the pod is created.
+		checkState := req.Operation == admissionv1.Create
+		resp := v.validateSelinuxNamespace(ctx, reqLogger, &pod, checkState)
+		if !resp.Allowed || req.Operation != admissionv1.Create || !operatorconfig.Get().PodAdmission.AuthorizeUse {
+			return resp
+		}
+		msg, err := v.authorizeUse(ctx, req.UserInfo, &pod)
+		if err != nil {
+			return review.Errored(500, err)
+		}
+		if msg != "" {
+			reqLogger.Info("Rejected pod", "reason", msg)
+			return review.Denied(msg, resp.Warnings...)
+		}
+		return resp
 	}
 
-	return webhook.Allowed("")
+	return review.Allowed()
 }
 
-func (v *ValidateNamespace) validateSelinuxNamespace(ctx context.Context, log logr.Logger, pod *corev1.Pod) webhook.AdmissionResponse {
-	secContext := pod.Spec.SecurityContext
-	if secContext != nil {
-		if valid, response := v.isSelinuxOptionsValidOrNil(ctx, log, secContext.SELinuxOptions, pod.Namespace); !valid {
-			return response
-		}
+// validatePodTemplate checks the pod template of a workload, so workloads
+// whose pods would be rejected are rejected up front.
+func (v *ValidateNamespace) validatePodTemplate(ctx context.Context, log logr.Logger, req *admissionv1.AdmissionRequest) review.Response {
+	template, ok, err := getPodTemplate(req)
+	if !ok {
+		log.Info("Got a request for the wrong resource.")
+		return review.Errored(400, fmt.Errorf("got a request for the wrong resource"))
+	}
+	if err != nil {
+		log.Info("ERROR: Unable to decode the workload")
+		return review.Errored(400, err)
+	}
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return review.Allowed()
 	}
 
-	for _, container := range pod.Spec.InitContainers {
-		if container.SecurityContext != nil {
-			if valid, response := v.isSelinuxOptionsValidOrNil(ctx, log, container.SecurityContext.SELinuxOptions, pod.Namespace); !valid {
-				return response
-			}
-		}
+	pod := &corev1.Pod{ObjectMeta: template.ObjectMeta, Spec: template.Spec}
+	pod.Namespace = req.Namespace
+	if msg, err := v.checkPolicyAnnotations(ctx, pod); err != nil {
+		return review.Errored(500, err)
+	} else if msg != "" {
+		return review.Denied(msg)
 	}
-	for _, container := range pod.Spec.Containers {
-		if container.SecurityContext != nil {
-			if valid, response := v.isSelinuxOptionsValidOrNil(ctx, log, container.SecurityContext.SELinuxOptions, pod.Namespace); !valid {
-				return response
-			}
-		}
+	// The pods are checked again when they're created, which is when
+	// their policy needs to be installed
+	return v.validateSelinuxNamespace(ctx, log, pod, false)
+}
+
+// checkPolicyAnnotations checks that the policies that the pod references
+// in its annotations exist. The policies are looked up when the pod is
+// created, so this only matters for pod templates.
+func (v *ValidateNamespace) checkPolicyAnnotations(ctx context.Context, pod *corev1.Pod) (string, error) {
+	keys := make([]string, 0, len(pod.Annotations))
+	for key := range pod.Annotations {
+		keys = append(keys, key)
 	}
-	for _, container := range pod.Spec.EphemeralContainers {
-		if container.SecurityContext != nil {
-			if valid, response := v.isSelinuxOptionsValidOrNil(ctx, log, container.SecurityContext.SELinuxOptions, pod.Namespace); !valid {
-				return response
-			}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := pod.Annotations[key]
+		if key != podpolicy.PolicyAnnotation && !strings.HasPrefix(key, podpolicy.ContainerPolicyAnnotationPrefix) {
+			continue
+		}
+		instance := &selinuxv1alpha1.SelinuxPolicy{}
+		err := v.client.Get(ctx, types.NamespacedName{Name: name, Namespace: pod.Namespace}, instance)
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("SelinuxPolicy '%s', referenced by annotation '%s', is not in namespace '%s'", name, key, pod.Namespace), nil
+		} else if err != nil {
+			return "", err
 		}
 	}
-	return webhook.Allowed("")
+	return "", nil
 }
 
-func (v *ValidateNamespace) isSelinuxOptionsValidOrNil(ctx context.Context, log logr.Logger, selinuxOpts *corev1.SELinuxOptions, ns string) (bool, webhook.AdmissionResponse) {
-	if selinuxOpts != nil {
-		allowed, msg, err := v.isAllowedSelinuxPolicy(ctx, log, selinuxOpts, ns)
+func (v *ValidateNamespace) validateSelinuxNamespace(ctx context.Context, log logr.Logger, pod *corev1.Pod, checkState bool) review.Response {
+	cfg, err := operatorconfig.Fetch(ctx, v.client)
+	if err != nil {
+		return review.Errored(500, err)
+	}
+
+	var warnings []string
+	for _, selinuxOpts := range podSelinuxOptions(pod) {
+		allowed, msg, err := v.isAllowedSelinuxPolicy(ctx, log, selinuxOpts, pod.Namespace)
 		if err != nil {
-			return false, webhook.Errored(500, err)
+			return review.Errored(500, err)
 		}
 		if !allowed {
-			return false, webhook.Denied(msg)
+			return review.Denied(msg)
+		}
+		if !checkState {
+			continue
+		}
+		msg, err = v.checkInstallState(ctx, selinuxOpts, pod.Namespace)
+		if err != nil {
+			return review.Errored(500, err)
+		}
+		if msg == "" || contains(warnings, msg) {
+			continue
+		}
+		switch cfg.PodAdmission.UninstalledPolicy {
+		case selinuxv1alpha1.UninstalledPolicyDeny:
+			return review.Denied(msg)
+		case selinuxv1alpha1.UninstalledPolicyWarn:
+			warnings = append(warnings, msg)
+		}
+	}
+	return review.Allowed(warnings...)
+}
+
+// podSelinuxOptions returns the SELinux options that are set in the pod
+// and its containers
+func podSelinuxOptions(pod *corev1.Pod) []*corev1.SELinuxOptions {
+	var opts []*corev1.SELinuxOptions
+	if pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.SELinuxOptions != nil {
+		opts = append(opts, pod.Spec.SecurityContext.SELinuxOptions)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.SecurityContext != nil && container.SecurityContext.SELinuxOptions != nil {
+			opts = append(opts, container.SecurityContext.SELinuxOptions)
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.SecurityContext != nil && container.SecurityContext.SELinuxOptions != nil {
+			opts = append(opts, container.SecurityContext.SELinuxOptions)
+		}
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.SecurityContext != nil && container.SecurityContext.SELinuxOptions != nil {
+			opts = append(opts, container.SecurityContext.SELinuxOptions)
 		}
 	}
-	return true, webhook.AdmissionResponse{}
+	return opts
 }
 
 func (v *ValidateNamespace) isAllowedSelinuxPolicy(ctx context.Context, log logr.Logger, selinuxOpts *corev1.SELinuxOptions, ns string) (bool, string, error) {
@@ -176,3 +243,41 @@ func (v *ValidateNamespace) isSelinuxPolicyInNamespace(ctx context.Context, log
 	}
 	return true, "", nil
 }
+
+// checkInstallState returns why the policy that the pod runs with can't be
+// used yet, if it's a policy of the operator that isn't installed.
+func (v *ValidateNamespace) checkInstallState(ctx context.Context, selinuxOpts *corev1.SELinuxOptions, ns string) (string, error) {
+	if !strings.HasSuffix(selinuxOpts.Type, ".process") || selinuxOpts.Type == ".process" {
+		return "", nil
+	}
+	// The type was already checked to be <name>_<ns>.process
+	policyName := strings.Split(selinuxOpts.Type, "_")[0]
+	instance := &selinuxv1alpha1.SelinuxPolicy{}
+	if err := v.client.Get(ctx, types.NamespacedName{Name: policyName, Namespace: ns}, instance); err != nil {
+		return "", err
+	}
+	state := instance.Status.State
+	if state == selinuxv1alpha1.PolicyStateInstalled {
+		return "", nil
+	}
+	if state == "" {
+		state = selinuxv1alpha1.PolicyStatePending
+	}
+	msg := fmt.Sprintf("SelinuxPolicy '%s' isn't installed yet, it's %s", policyName, state)
+	if state == selinuxv1alpha1.PolicyStateError {
+		msg = fmt.Sprintf("SelinuxPolicy '%s' couldn't be installed, it's %s", policyName, state)
+	}
+	if instance.Status.Message != "" {
+		msg += ": " + instance.Status.Message
+	}
+	return msg, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package namespace
+
+import (
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getPodTemplate decodes the pod template of the workload in the request.
+// It returns false if the request isn't for a workload that's known to
+// have one.
+func getPodTemplate(req *admissionv1.AdmissionRequest) (*corev1.PodTemplateSpec, bool, error) {
+	resource := metav1.GroupResource{Group: req.Resource.Group, Resource: req.Resource.Resource}
+	var template *corev1.PodTemplateSpec
+	var err error
+	switch resource {
+	case metav1.GroupResource{Group: "apps", Resource: "deployments"}:
+		obj := &appsv1.Deployment{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = &obj.Spec.Template
+	case metav1.GroupResource{Group: "apps", Resource: "statefulsets"}:
+		obj := &appsv1.StatefulSet{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = &obj.Spec.Template
+	case metav1.GroupResource{Group: "apps", Resource: "daemonsets"}:
+		obj := &appsv1.DaemonSet{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = &obj.Spec.Template
+	case metav1.GroupResource{Group: "apps", Resource: "replicasets"}:
+		obj := &appsv1.ReplicaSet{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = &obj.Spec.Template
+	case metav1.GroupResource{Group: "", Resource: "replicationcontrollers"}:
+		obj := &corev1.ReplicationController{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = obj.Spec.Template
+	case metav1.GroupResource{Group: "batch", Resource: "jobs"}:
+		obj := &batchv1.Job{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = &obj.Spec.Template
+	case metav1.GroupResource{Group: "batch", Resource: "cronjobs"}:
+		// The job template is the same in every version of CronJobs
+		obj := &batchv1beta1.CronJob{}
+		err = json.Unmarshal(req.Object.Raw, obj)
+		template = &obj.Spec.JobTemplate.Spec.Template
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("got a request but couldn't decode the %s", req.Resource.Resource)
+	}
+	if template == nil {
+		template = &corev1.PodTemplateSpec{}
+	}
+	return template, true, nil
+}
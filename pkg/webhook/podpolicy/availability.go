@@ -0,0 +1,73 @@
+package podpolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+// requireInstalledNodes adds a required node affinity for every policy of
+// the namespace that the pod runs with, so it's only scheduled onto the
+// nodes where the policies are installed.
+func requireInstalledNodes(pod *corev1.Pod, ns string) {
+	var requirements []corev1.NodeSelectorRequirement
+	for _, name := range utils.GetPodPolicyNames(pod, ns) {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      utils.GetPolicyNodeLabel(name, ns),
+			Operator: corev1.NodeSelectorOpExists,
+		})
+	}
+	if len(requirements) == 0 {
+		return
+	}
+
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := pod.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	// The terms are ORed, so every one of them needs the requirements
+	for i := range selector.NodeSelectorTerms {
+		term := &selector.NodeSelectorTerms[i]
+		for _, requirement := range requirements {
+			if !hasRequirement(term.MatchExpressions, requirement) {
+				term.MatchExpressions = append(term.MatchExpressions, requirement)
+			}
+		}
+	}
+}
+
+// addReadinessGate adds the readiness gate that the operator sets once the
+// policies of the namespace that the pod runs with are installed on its
+// node.
+func addReadinessGate(pod *corev1.Pod, ns string) {
+	if len(utils.GetPodPolicyNames(pod, ns)) == 0 {
+		return
+	}
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == utils.PolicyReadinessGate {
+			return
+		}
+	}
+	pod.Spec.ReadinessGates = append(pod.Spec.ReadinessGates, corev1.PodReadinessGate{
+		ConditionType: utils.PolicyReadinessGate,
+	})
+}
+
+func hasRequirement(requirements []corev1.NodeSelectorRequirement, requirement corev1.NodeSelectorRequirement) bool {
+	for _, r := range requirements {
+		if r.Key == requirement.Key && r.Operator == requirement.Operator {
+			return true
+		}
+	}
+	return false
+}
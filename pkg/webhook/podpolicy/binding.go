@@ -0,0 +1,145 @@
+package podpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+)
+
+// applyBindings sets the SELinux type of the containers matched by the
+// SelinuxPolicyBindings of the namespace. Containers that reference a
+// policy with an annotation are left as they are. It returns why the pod is
+// rejected, if it is.
+func (i *InjectPolicy) applyBindings(ctx context.Context, pod *corev1.Pod, ns string) (string, error) {
+	bindings := &selinuxv1alpha1.SelinuxPolicyBindingList{}
+	if err := i.client.List(ctx, bindings, client.InNamespace(ns)); err != nil {
+		return "", err
+	}
+	if len(bindings.Items) == 0 {
+		return "", nil
+	}
+
+	for _, container := range podContainers(pod) {
+		if hasPolicyAnnotation(pod, container.Name) {
+			continue
+		}
+		var bound *selinuxv1alpha1.SelinuxPolicyBinding
+		for j := range bindings.Items {
+			binding := &bindings.Items[j]
+			matches, err := bindingMatches(binding, pod, container)
+			if err != nil {
+				return "", err
+			}
+			if !matches {
+				continue
+			}
+			if bound != nil && bound.Spec.PolicyName != binding.Spec.PolicyName {
+				return fmt.Sprintf("container '%s' is bound to both policy '%s' by '%s' and policy '%s' by '%s'",
+					container.Name, bound.Spec.PolicyName, bound.Name, binding.Spec.PolicyName, binding.Name), nil
+			}
+			bound = binding
+		}
+		if bound == nil {
+			continue
+		}
+
+		usage, msg, err := i.getUsage(ctx, bound.Spec.PolicyName, ns)
+		if err != nil || msg != "" {
+			return msg, err
+		}
+		if container.SecurityContext == nil {
+			container.SecurityContext = &corev1.SecurityContext{}
+		}
+		if container.SecurityContext.SELinuxOptions == nil {
+			container.SecurityContext.SELinuxOptions = &corev1.SELinuxOptions{}
+		}
+		container.SecurityContext.SELinuxOptions.Type = usage
+	}
+	return "", nil
+}
+
+func hasPolicyAnnotation(pod *corev1.Pod, container string) bool {
+	if _, ok := pod.Annotations[PolicyAnnotation]; ok {
+		return true
+	}
+	_, ok := pod.Annotations[ContainerPolicyAnnotationPrefix+container]
+	return ok
+}
+
+func podContainers(pod *corev1.Pod) []*corev1.Container {
+	var containers []*corev1.Container
+	for i := range pod.Spec.InitContainers {
+		containers = append(containers, &pod.Spec.InitContainers[i])
+	}
+	for i := range pod.Spec.Containers {
+		containers = append(containers, &pod.Spec.Containers[i])
+	}
+	return containers
+}
+
+func bindingMatches(binding *selinuxv1alpha1.SelinuxPolicyBinding, pod *corev1.Pod, container *corev1.Container) (bool, error) {
+	if binding.Spec.ServiceAccountName != "" && binding.Spec.ServiceAccountName != serviceAccountName(pod) {
+		return false, nil
+	}
+	if binding.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(binding.Spec.Selector)
+		if err != nil {
+			return false, fmt.Errorf("invalid selector in binding %s: %w", binding.Name, err)
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			return false, nil
+		}
+	}
+	if len(binding.Spec.Images) == 0 {
+		return true, nil
+	}
+	for _, image := range binding.Spec.Images {
+		if imageMatches(image, container.Image) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// serviceAccountName returns the service account the pod runs as, which
+// isn't set yet when the pod is created without one.
+func serviceAccountName(pod *corev1.Pod) string {
+	if pod.Spec.ServiceAccountName != "" {
+		return pod.Spec.ServiceAccountName
+	}
+	if pod.Spec.DeprecatedServiceAccount != "" {
+		return pod.Spec.DeprecatedServiceAccount
+	}
+	return "default"
+}
+
+// imageMatches tells whether the image is the given full reference, has
+// the given name regardless of the tag or digest, or has the given digest.
+func imageMatches(pattern, image string) bool {
+	if pattern == image {
+		return true
+	}
+	if strings.HasPrefix(pattern, "sha256:") {
+		return strings.HasSuffix(image, "@"+pattern)
+	}
+	return imageName(image) == pattern
+}
+
+// imageName strips the digest and tag of an image reference
+func imageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	// A colon before the last slash separates the registry's port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
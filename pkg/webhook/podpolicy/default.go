@@ -0,0 +1,55 @@
+package podpolicy
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// applyDefault sets the SELinux type of the containers that don't have one
+// from the namespace's default policy, unless the pod has a type for all
+// its containers or opted out. It returns why the pod is rejected, if it
+// is.
+func (i *InjectPolicy) applyDefault(ctx context.Context, pod *corev1.Pod, ns string) (string, error) {
+	if pod.Annotations[SkipDefaultPolicyAnnotation] == "true" {
+		return "", nil
+	}
+	if pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.SELinuxOptions != nil &&
+		pod.Spec.SecurityContext.SELinuxOptions.Type != "" {
+		return "", nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := i.client.Get(ctx, types.NamespacedName{Name: ns}, namespace); err != nil {
+		return "", err
+	}
+	name := namespace.Annotations[DefaultPolicyAnnotation]
+	if name == "" {
+		return "", nil
+	}
+
+	var usage string
+	for _, container := range podContainers(pod) {
+		if container.SecurityContext != nil && container.SecurityContext.SELinuxOptions != nil &&
+			container.SecurityContext.SELinuxOptions.Type != "" {
+			continue
+		}
+		if usage == "" {
+			var msg string
+			var err error
+			usage, msg, err = i.getUsage(ctx, name, ns)
+			if err != nil || msg != "" {
+				return msg, err
+			}
+		}
+		if container.SecurityContext == nil {
+			container.SecurityContext = &corev1.SecurityContext{}
+		}
+		if container.SecurityContext.SELinuxOptions == nil {
+			container.SecurityContext.SELinuxOptions = &corev1.SELinuxOptions{}
+		}
+		container.SecurityContext.SELinuxOptions.Type = usage
+	}
+	return "", nil
+}
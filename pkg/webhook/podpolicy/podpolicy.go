@@ -0,0 +1,182 @@
+// This webhook sets the seLinuxOptions of the pods that reference a
+// SelinuxPolicy by name, so they don't need to spell out the type that
+// the policy's process runs with, and of the pods that a
+// SelinuxPolicyBinding attaches a policy to. The containers that are left
+// without a type run with the namespace's default policy, if it has one.
+// Pods referencing a policy that doesn't exist in their namespace are
+// rejected. Pods running with a policy are only scheduled onto the nodes
+// where the policy is installed, or, depending on the operator's settings,
+// aren't ready until it is.
+
+package podpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/review"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/server"
+)
+
+const (
+	webhookPath = "/mutate-pod-selinuxpolicy"
+
+	// PolicyAnnotation references the SelinuxPolicy, in the pod's
+	// namespace, that the pod runs with.
+	PolicyAnnotation = "selinux.openshift.io/policy"
+	// ContainerPolicyAnnotationPrefix is followed by the name of a
+	// container, to reference the SelinuxPolicy that only the container
+	// runs with. It takes precedence over the pod's policy.
+	ContainerPolicyAnnotationPrefix = PolicyAnnotation + "."
+	// DefaultPolicyAnnotation is set on a Namespace to the SelinuxPolicy
+	// that the containers without a SELinux type run with.
+	DefaultPolicyAnnotation = "selinux.openshift.io/default-policy"
+	// SkipDefaultPolicyAnnotation is set to "true" on pods that run with
+	// the SELinux types in their manifest, even if they have none.
+	SkipDefaultPolicyAnnotation = "selinux.openshift.io/skip-default-policy"
+)
+
+var log = logf.Log.WithName("webhook_podpolicy")
+
+// InjectPolicy sets the seLinuxOptions of pods from the policies they
+// reference.
+type InjectPolicy struct {
+	client client.Client
+}
+
+// Add registers the webhook in the manager's webhook server.
+func Add(mgr manager.Manager) error {
+	injector := &InjectPolicy{
+		client: mgr.GetClient(),
+	}
+	return server.Register(mgr, webhookPath, &review.Webhook{Handler: injector.Handle})
+}
+
+// Handle handles requests for pods
+func (i *InjectPolicy) Handle(ctx context.Context, req *admissionv1.AdmissionRequest) review.Response {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+
+	podResource := metav1.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	if req.Resource != podResource {
+		reqLogger.Info("Got a request for the wrong resource.")
+		return review.Errored(http.StatusBadRequest, fmt.Errorf("got a request for the wrong resource"))
+	}
+	if req.Operation != admissionv1.Create {
+		return review.Allowed()
+	}
+
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return review.Errored(http.StatusBadRequest, fmt.Errorf("got a request but couldn't decode the pod"))
+	}
+	mutated := pod.DeepCopy()
+	msg, err := i.injectPolicies(ctx, mutated, req.Namespace)
+	if err == nil && msg == "" {
+		msg, err = i.applyBindings(ctx, mutated, req.Namespace)
+	}
+	if err == nil && msg == "" {
+		msg, err = i.applyDefault(ctx, mutated, req.Namespace)
+	}
+	if err != nil {
+		return review.Errored(http.StatusInternalServerError, err)
+	}
+	if msg != "" {
+		reqLogger.Info("Rejected pod", "reason", msg)
+		return review.Denied(msg)
+	}
+	cfg, err := operatorconfig.Fetch(ctx, i.client)
+	if err != nil {
+		return review.Errored(http.StatusInternalServerError, err)
+	}
+	if cfg.PodAdmission.Availability == selinuxv1alpha1.PolicyAvailabilityReadinessGate {
+		addReadinessGate(mutated, req.Namespace)
+	} else {
+		requireInstalledNodes(mutated, req.Namespace)
+	}
+	return review.PatchedFromRaw(req.Object.Raw, mutated)
+}
+
+// injectPolicies sets the SELinux type of the pod and its containers from
+// the policies referenced in its annotations. It returns why the pod is
+// rejected, if it is.
+func (i *InjectPolicy) injectPolicies(ctx context.Context, pod *corev1.Pod, ns string) (string, error) {
+	// The annotations are sorted so the same pod is always rejected for
+	// the same reason
+	keys := make([]string, 0, len(pod.Annotations))
+	for key := range pod.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := pod.Annotations[key]
+		var opts **corev1.SELinuxOptions
+		if key == PolicyAnnotation {
+			if pod.Spec.SecurityContext == nil {
+				pod.Spec.SecurityContext = &corev1.PodSecurityContext{}
+			}
+			opts = &pod.Spec.SecurityContext.SELinuxOptions
+		} else if strings.HasPrefix(key, ContainerPolicyAnnotationPrefix) {
+			container := findContainer(pod, strings.TrimPrefix(key, ContainerPolicyAnnotationPrefix))
+			if container == nil {
+				return fmt.Sprintf("annotation '%s' refers to a container that the pod doesn't have", key), nil
+			}
+			if container.SecurityContext == nil {
+				container.SecurityContext = &corev1.SecurityContext{}
+			}
+			opts = &container.SecurityContext.SELinuxOptions
+		} else {
+			continue
+		}
+
+		usage, msg, err := i.getUsage(ctx, name, ns)
+		if err != nil || msg != "" {
+			return msg, err
+		}
+		if *opts == nil {
+			*opts = &corev1.SELinuxOptions{}
+		}
+		(*opts).Type = usage
+	}
+	return "", nil
+}
+
+// getUsage gets the SELinux type that pods using the given policy run with
+func (i *InjectPolicy) getUsage(ctx context.Context, name, ns string) (string, string, error) {
+	policy := &selinuxv1alpha1.SelinuxPolicy{}
+	err := i.client.Get(ctx, types.NamespacedName{Name: name, Namespace: ns}, policy)
+	if errors.IsNotFound(err) {
+		return "", fmt.Sprintf("SelinuxPolicy '%s' is not in namespace '%s'", name, ns), nil
+	} else if err != nil {
+		return "", "", err
+	}
+	if policy.Status.Usage != "" {
+		return policy.Status.Usage, "", nil
+	}
+	// The policy wasn't reconciled yet
+	return utils.GetPolicyUsage(policy.Name, policy.Namespace), "", nil
+}
+
+func findContainer(pod *corev1.Pod, name string) *corev1.Container {
+	for _, container := range podContainers(pod) {
+		if container.Name == name {
+			return container
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package registration
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+const (
+	// CertSecretName is the name of the Secret, in the operator's
+	// namespace, holding the CA and the certificate that the operator
+	// issues for the webhook server.
+	CertSecretName = "selinux-operator-webhook-certs"
+
+	caCertKey = "ca.crt"
+	caKeyKey  = "ca.key"
+	// The CA that was replaced, which is still trusted until it expires
+	previousCACertKey = "previous-ca.crt"
+
+	caValidity   = 2 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+)
+
+// syncCertificates makes sure the webhook server has a certificate issued
+// by the operator's CA, and returns the CA bundle that the certificate is
+// verified with. Certificates are renewed once they're past two thirds of
+// their validity, so every replica picks them up before they expire.
+func (r *Registrar) syncCertificates(ctx context.Context, certDir string) ([]byte, error) {
+	var data map[string][]byte
+	var err error
+	// Replicas racing to issue the certificate use the one of the
+	// replica that won
+	for attempt := 0; attempt < 3; attempt++ {
+		data, err = r.issueCertificates(ctx)
+		if !errors.IsConflict(err) && !errors.IsAlreadyExists(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeIfChanged(filepath.Join(certDir, corev1.TLSPrivateKeyKey), data[corev1.TLSPrivateKeyKey]); err != nil {
+		return nil, err
+	}
+	if err := writeIfChanged(filepath.Join(certDir, corev1.TLSCertKey), data[corev1.TLSCertKey]); err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, data[caCertKey]...), data[previousCACertKey]...), nil
+}
+
+// issueCertificates renews the certificates held in the operator's Secret
+// if needed, and returns the Secret's data.
+func (r *Registrar) issueCertificates(ctx context.Context) (map[string][]byte, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: CertSecretName, Namespace: utils.GetOperatorNamespace()}
+	err := r.reader.Get(ctx, key, secret)
+	if errors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Type:       corev1.SecretTypeTLS,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	data, changed, err := renewCertificates(secret.Data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		log.Info("Issuing the webhook server's certificate")
+		secret.Data = data
+		if secret.ResourceVersion == "" {
+			err = r.client.Create(ctx, secret)
+		} else {
+			err = r.client.Update(ctx, secret)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// renewCertificates issues the CA and the certificate held in the given
+// Secret data if they're missing or about to expire. It returns the new
+// data, and whether it changed.
+func renewCertificates(in map[string][]byte, now time.Time) (map[string][]byte, bool, error) {
+	data := map[string][]byte{}
+	for k, v := range in {
+		data[k] = v
+	}
+	changed := false
+
+	ca, caKey, err := parseKeyPair(data[caCertKey], data[caKeyKey])
+	if err != nil || needsRenewal(ca, now) {
+		if ca != nil && now.Before(ca.NotAfter) {
+			data[previousCACertKey] = data[caCertKey]
+		} else {
+			delete(data, previousCACertKey)
+		}
+		ca, caKey, err = newCA(now)
+		if err != nil {
+			return nil, false, err
+		}
+		data[caCertKey] = encodeCert(ca)
+		if data[caKeyKey], err = encodeKey(caKey); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+	if previous, _ := parseCert(data[previousCACertKey]); previous != nil && !now.Before(previous.NotAfter) {
+		delete(data, previousCACertKey)
+		changed = true
+	}
+
+	cert, _, err := parseKeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	if err != nil || needsRenewal(cert, now) || cert.CheckSignatureFrom(ca) != nil || !hasDNSNames(cert, dnsNames()) {
+		cert, certKey, err := newServingCert(ca, caKey, now)
+		if err != nil {
+			return nil, false, err
+		}
+		data[corev1.TLSCertKey] = encodeCert(cert)
+		if data[corev1.TLSPrivateKeyKey], err = encodeKey(certKey); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+	return data, changed, nil
+}
+
+// needsRenewal tells whether the certificate is past two thirds of its
+// validity
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+	return now.After(cert.NotBefore.Add(validity * 2 / 3))
+}
+
+// dnsNames returns the names that the webhook service is reached at
+func dnsNames() []string {
+	ns := utils.GetOperatorNamespace()
+	return []string{
+		ServiceName,
+		ServiceName + "." + ns,
+		ServiceName + "." + ns + ".svc",
+		ServiceName + "." + ns + ".svc.cluster.local",
+	}
+}
+
+func hasDNSNames(cert *x509.Certificate, names []string) bool {
+	for _, name := range names {
+		if cert.VerifyHostname(name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func newCA(now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "selinux-operator-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return issue(template, nil, nil)
+}
+
+func newServingCert(ca *x509.Certificate, caKey *ecdsa.PrivateKey, now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	names := dnsNames()
+	notAfter := now.Add(certValidity)
+	// The certificate can't outlive the CA that issued it
+	if notAfter.After(ca.NotAfter) {
+		notAfter = ca.NotAfter
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: names[2]},
+		DNSNames:    names,
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return issue(template, ca, caKey)
+}
+
+// issue creates a key and a certificate for it from the template. The
+// certificate is self-signed if no parent is given.
+func issue(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func parseCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("expected a PEM-encoded certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parseKeyPair(certData, keyData []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	cert, err := parseCert(certData)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(keyData)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, nil, fmt.Errorf("expected a PEM-encoded EC private key")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func encodeCert(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// writeIfChanged writes the file, unless it already has the given content.
+// The webhook server reloads its certificate whenever the files change.
+func writeIfChanged(path string, data []byte) error {
+	current, err := ioutil.ReadFile(path)
+	if err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,266 @@
+package registration
+
+import (
+	"context"
+	"reflect"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+)
+
+const (
+	// ServiceName is the name of the Service, in the operator's
+	// namespace, that the API server reaches the webhooks through.
+	ServiceName = "selinux-namespace-webhook"
+	// ServicePort is the port of the Service. It's forwarded to the port
+	// the webhook server listens on.
+	ServicePort = 8443
+
+	timeoutSeconds = 2
+)
+
+// The namespaces of the cluster's own components and the operator's own
+// namespace are left alone, so pods there are never held back by the
+// operator.
+func podNamespaceSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      "openshift.io/run-level",
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{"0", "1"},
+			},
+			{
+				Key:      "kubernetes.io/metadata.name",
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{utils.GetOperatorNamespace(), "kube-system"},
+			},
+		},
+	}
+}
+
+func validatingConfigurations() []*admissionregistrationv1.ValidatingWebhookConfiguration {
+	return []*admissionregistrationv1.ValidatingWebhookConfiguration{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "selinux-policy-in-pod-namespace.openshift.io"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				validatingWebhook("selinux-policy-in-pod-namespace.openshift.io", "/validate-selinuxpolicy-namespace",
+					podNamespaceSelector(),
+					rule("", []string{"v1"}, "pods", "replicationcontrollers"),
+					rule("apps", []string{"v1"}, "deployments", "statefulsets", "daemonsets", "replicasets"),
+					rule("batch", []string{"v1"}, "jobs"),
+					rule("batch", []string{"v1", "v1beta1"}, "cronjobs")),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "selinuxpolicy-validation.openshift.io"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				validatingWebhook("selinuxpolicy-validation.openshift.io", "/validate-selinuxpolicy", nil,
+					rule("selinux.openshift.io", []string{"v1alpha1"}, "selinuxpolicies")),
+			},
+		},
+	}
+}
+
+func mutatingConfigurations() []*admissionregistrationv1.MutatingWebhookConfiguration {
+	return []*admissionregistrationv1.MutatingWebhookConfiguration{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "selinuxpolicy-approval.openshift.io"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				mutatingWebhook("selinuxpolicy-author.openshift.io", "/mutate-selinuxpolicy", nil,
+					admissionregistrationv1.NeverReinvocationPolicy,
+					rule("selinux.openshift.io", []string{"v1alpha1"}, "selinuxpolicies")),
+				mutatingWebhook("selinuxpolicy-approver.openshift.io", "/mutate-selinuxpolicyapproval", nil,
+					admissionregistrationv1.NeverReinvocationPolicy,
+					rule("selinux.openshift.io", []string{"v1alpha1"}, "selinuxpolicyapprovals")),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "selinux-policy-injection.openshift.io"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				mutatingWebhook("selinux-policy-injection.openshift.io", "/mutate-pod-selinuxpolicy", podNamespaceSelector(),
+					admissionregistrationv1.IfNeededReinvocationPolicy,
+					createRule("", []string{"v1"}, "pods")),
+			},
+		},
+	}
+}
+
+// rule matches the creation and update of the given resources
+func rule(group string, versions []string, resources ...string) admissionregistrationv1.RuleWithOperations {
+	r := createRule(group, versions, resources...)
+	r.Operations = append(r.Operations, admissionregistrationv1.Update)
+	return r
+}
+
+// createRule matches the creation of the given resources
+func createRule(group string, versions []string, resources ...string) admissionregistrationv1.RuleWithOperations {
+	scope := admissionregistrationv1.NamespacedScope
+	return admissionregistrationv1.RuleWithOperations{
+		Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+		Rule: admissionregistrationv1.Rule{
+			APIGroups:   []string{group},
+			APIVersions: versions,
+			Resources:   resources,
+			Scope:       &scope,
+		},
+	}
+}
+
+// The fields that the API server defaults are all set, so the webhooks
+// can be compared with the ones that are stored.
+func clientConfig(path string) admissionregistrationv1.WebhookClientConfig {
+	port := int32(ServicePort)
+	return admissionregistrationv1.WebhookClientConfig{
+		Service: &admissionregistrationv1.ServiceReference{
+			Namespace: utils.GetOperatorNamespace(),
+			Name:      ServiceName,
+			Path:      &path,
+			Port:      &port,
+		},
+	}
+}
+
+func selectorOrAll(selector *metav1.LabelSelector) *metav1.LabelSelector {
+	if selector == nil {
+		return &metav1.LabelSelector{}
+	}
+	return selector.DeepCopy()
+}
+
+func validatingWebhook(name, path string, namespaceSelector *metav1.LabelSelector,
+	rules ...admissionregistrationv1.RuleWithOperations) admissionregistrationv1.ValidatingWebhook {
+	failurePolicy := admissionregistrationv1.Fail
+	matchPolicy := admissionregistrationv1.Equivalent
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	timeout := int32(timeoutSeconds)
+	return admissionregistrationv1.ValidatingWebhook{
+		Name:                    name,
+		ClientConfig:            clientConfig(path),
+		Rules:                   rules,
+		FailurePolicy:           &failurePolicy,
+		MatchPolicy:             &matchPolicy,
+		NamespaceSelector:       selectorOrAll(namespaceSelector),
+		ObjectSelector:          &metav1.LabelSelector{},
+		SideEffects:             &sideEffects,
+		TimeoutSeconds:          &timeout,
+		AdmissionReviewVersions: []string{"v1", "v1beta1"},
+	}
+}
+
+func mutatingWebhook(name, path string, namespaceSelector *metav1.LabelSelector,
+	reinvocationPolicy admissionregistrationv1.ReinvocationPolicyType,
+	rules ...admissionregistrationv1.RuleWithOperations) admissionregistrationv1.MutatingWebhook {
+	v := validatingWebhook(name, path, namespaceSelector, rules...)
+	return admissionregistrationv1.MutatingWebhook{
+		Name:                    v.Name,
+		ClientConfig:            v.ClientConfig,
+		Rules:                   v.Rules,
+		FailurePolicy:           v.FailurePolicy,
+		MatchPolicy:             v.MatchPolicy,
+		NamespaceSelector:       v.NamespaceSelector,
+		ObjectSelector:          v.ObjectSelector,
+		SideEffects:             v.SideEffects,
+		TimeoutSeconds:          v.TimeoutSeconds,
+		AdmissionReviewVersions: v.AdmissionReviewVersions,
+		ReinvocationPolicy:      &reinvocationPolicy,
+	}
+}
+
+// syncConfigurations creates the webhook configurations, or updates them
+// if they changed. Without a CA bundle, the one of the stored webhooks is
+// kept.
+func (r *Registrar) syncConfigurations(ctx context.Context, caBundle []byte) error {
+	for _, desired := range validatingConfigurations() {
+		existing := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		err := r.reader.Get(ctx, types.NamespacedName{Name: desired.Name}, existing)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		found := err == nil
+		stored := map[string][]byte{}
+		for _, w := range existing.Webhooks {
+			stored[w.Name] = w.ClientConfig.CABundle
+		}
+		for i := range desired.Webhooks {
+			desired.Webhooks[i].ClientConfig.CABundle = bundleFor(caBundle, stored[desired.Webhooks[i].Name])
+		}
+		if !found {
+			log.Info("Creating the webhook configuration", "Name", desired.Name)
+			if err := utils.IgnoreAlreadyExists(r.client.Create(ctx, desired)); err != nil {
+				return err
+			}
+		} else if !reflect.DeepEqual(existing.Webhooks, desired.Webhooks) {
+			log.Info("Updating the webhook configuration", "Name", desired.Name)
+			existing.Webhooks = desired.Webhooks
+			if err := r.client.Update(ctx, existing); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, desired := range mutatingConfigurations() {
+		existing := &admissionregistrationv1.MutatingWebhookConfiguration{}
+		err := r.reader.Get(ctx, types.NamespacedName{Name: desired.Name}, existing)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		found := err == nil
+		stored := map[string][]byte{}
+		for _, w := range existing.Webhooks {
+			stored[w.Name] = w.ClientConfig.CABundle
+		}
+		for i := range desired.Webhooks {
+			desired.Webhooks[i].ClientConfig.CABundle = bundleFor(caBundle, stored[desired.Webhooks[i].Name])
+		}
+		if !found {
+			log.Info("Creating the webhook configuration", "Name", desired.Name)
+			if err := utils.IgnoreAlreadyExists(r.client.Create(ctx, desired)); err != nil {
+				return err
+			}
+		} else if !reflect.DeepEqual(existing.Webhooks, desired.Webhooks) {
+			log.Info("Updating the webhook configuration", "Name", desired.Name)
+			existing.Webhooks = desired.Webhooks
+			if err := r.client.Update(ctx, existing); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncService forwards the port of the webhook Service to the port the
+// webhook server listens on. The Service is deployed along with the
+// operator.
+func (r *Registrar) syncService(ctx context.Context, webhookPort int32) error {
+	svc := &corev1.Service{}
+	if err := r.reader.Get(ctx, types.NamespacedName{Name: ServiceName, Namespace: utils.GetOperatorNamespace()}, svc); err != nil {
+		return err
+	}
+	targetPort := intstr.FromInt(int(webhookPort))
+	changed := false
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == ServicePort && svc.Spec.Ports[i].TargetPort != targetPort {
+			svc.Spec.Ports[i].TargetPort = targetPort
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("Updating the webhook Service", "TargetPort", webhookPort)
+	return r.client.Update(ctx, svc)
+}
+
+func bundleFor(caBundle, stored []byte) []byte {
+	if len(caBundle) == 0 {
+		return stored
+	}
+	return caBundle
+}
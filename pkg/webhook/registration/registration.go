@@ -0,0 +1,81 @@
+// Package registration registers the operator's webhooks with the API
+// server. It keeps the webhook configurations in sync, and issues the
+// webhook server's certificate unless an external one is used.
+package registration
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+)
+
+// syncInterval is how often the certificates, the webhook Service and the
+// webhook configurations are checked
+const syncInterval = 10 * time.Minute
+
+var log = logf.Log.WithName("webhook_registration")
+
+// Registrar keeps the webhooks registered.
+type Registrar struct {
+	client client.Client
+	// Reads straight from the API server, so the webhook configurations
+	// and Secrets don't need to be cached
+	reader client.Reader
+}
+
+// Add adds the registrar to the manager.
+func Add(mgr manager.Manager) error {
+	return mgr.Add(&Registrar{
+		client: mgr.GetClient(),
+		reader: mgr.GetAPIReader(),
+	})
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface. Every
+// replica of the operator serves the webhooks, so every replica needs the
+// certificate.
+func (*Registrar) NeedLeaderElection() bool {
+	return false
+}
+
+// Start syncs the webhooks periodically, and whenever the operator
+// settings change, until the stop channel is closed.
+func (r *Registrar) Start(stop <-chan struct{}) error {
+	changes := operatorconfig.Subscribe()
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.sync(context.TODO()); err != nil {
+			log.Error(err, "Failed to register the webhooks")
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		case <-changes:
+		}
+	}
+}
+
+func (r *Registrar) sync(ctx context.Context) error {
+	cfg, err := operatorconfig.Fetch(ctx, r.reader)
+	if err != nil {
+		return err
+	}
+	caBundle := []byte(cfg.WebhookCABundle)
+	if cfg.WebhookCertificates != selinuxv1alpha1.WebhookCertificatesExternal {
+		if caBundle, err = r.syncCertificates(ctx, cfg.WebhookCertDir); err != nil {
+			return err
+		}
+	}
+	if err := r.syncService(ctx, cfg.WebhookPort); err != nil {
+		return err
+	}
+	return r.syncConfigurations(ctx, caBundle)
+}
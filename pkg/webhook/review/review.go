@@ -0,0 +1,203 @@
+// Package review serves admission webhooks whose responses need more than
+// what the controller-runtime webhooks support, such as warnings for the
+// user. Both the admission.k8s.io/v1 and v1beta1 AdmissionReviews are
+// served, the response has the same version as the request.
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("webhook_review")
+
+// Response is the outcome of reviewing an admission request.
+type Response struct {
+	Allowed bool
+	// The HTTP status code and the reason for rejecting the request
+	Code    int32
+	Message string
+	// A JSON patch that is applied to the object of an allowed request
+	Patch []byte
+	// Shown to the user whether the request is allowed or not
+	Warnings []string
+}
+
+// Allowed allows the request, showing the given warnings to the user.
+func Allowed(warnings ...string) Response {
+	return Response{Allowed: true, Code: http.StatusOK, Warnings: warnings}
+}
+
+// Patched allows the request once the given JSON patch is applied to the
+// object.
+func Patched(patch []byte, warnings ...string) Response {
+	return Response{Allowed: true, Code: http.StatusOK, Patch: patch, Warnings: warnings}
+}
+
+// PatchedFromRaw allows the request once the object is changed from the
+// original raw object in the request into the mutated one.
+func PatchedFromRaw(original []byte, mutated interface{}, warnings ...string) Response {
+	current, err := json.Marshal(mutated)
+	if err != nil {
+		return Errored(http.StatusInternalServerError, err)
+	}
+	ops, err := jsonpatch.CreatePatch(original, current)
+	if err != nil {
+		return Errored(http.StatusInternalServerError, err)
+	}
+	if len(ops) == 0 {
+		return Allowed(warnings...)
+	}
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return Errored(http.StatusInternalServerError, err)
+	}
+	return Patched(patch, warnings...)
+}
+
+// AnnotationPatch returns a JSON patch that sets an annotation of an
+// object that has the given annotations.
+func AnnotationPatch(annotations map[string]string, key, value string) []byte {
+	var op interface{}
+	if annotations == nil {
+		op = map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations",
+			"value": map[string]string{key: value},
+		}
+	} else {
+		// "/" needs to be escaped in JSON pointers
+		escaped := strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		op = map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations/" + escaped,
+			"value": value,
+		}
+	}
+	patch, _ := json.Marshal([]interface{}{op})
+	return patch
+}
+
+// Denied rejects the request for the given reason.
+func Denied(msg string, warnings ...string) Response {
+	return Response{Code: http.StatusForbidden, Message: msg, Warnings: warnings}
+}
+
+// Errored rejects the request because it couldn't be reviewed. Requests
+// that the webhook can't handle are rejected with a 4xx code, and requests
+// that failed to be reviewed with a 5xx code.
+func Errored(code int32, err error) Response {
+	return Response{Code: code, Message: err.Error()}
+}
+
+// HandlerFunc reviews an admission request. The requests of every version
+// of the AdmissionReview are passed as admission.k8s.io/v1 requests, which
+// they're identical to.
+type HandlerFunc func(ctx context.Context, req *admissionv1.AdmissionRequest) Response
+
+// Webhook serves a HandlerFunc over HTTP.
+type Webhook struct {
+	Handler HandlerFunc
+}
+
+// The AdmissionReview sent back to the API server. The warnings aren't
+// part of the API types the operator is built with, API servers that
+// don't support them ignore them.
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Response   *admissionResponse `json:"response"`
+}
+
+type admissionResponse struct {
+	UID       types.UID      `json:"uid"`
+	Allowed   bool           `json:"allowed"`
+	Result    *metav1.Status `json:"status,omitempty"`
+	Patch     []byte         `json:"patch,omitempty"`
+	PatchType *string        `json:"patchType,omitempty"`
+	Warnings  []string       `json:"warnings,omitempty"`
+}
+
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType != "application/json" {
+		http.Error(w, fmt.Sprintf("unsupported content type '%s'", contentType), http.StatusBadRequest)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "unable to read the request", http.StatusBadRequest)
+		return
+	}
+	review := admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "unable to decode the admission review", http.StatusBadRequest)
+		return
+	}
+	apiVersion := review.APIVersion
+	if apiVersion != admissionv1.SchemeGroupVersion.String() && apiVersion != admissionv1beta1.SchemeGroupVersion.String() {
+		http.Error(w, fmt.Sprintf("unsupported admission review version '%s'", apiVersion), http.StatusBadRequest)
+		return
+	}
+
+	resp := wh.Handler(r.Context(), review.Request)
+	out := admissionReview{
+		APIVersion: apiVersion,
+		Kind:       "AdmissionReview",
+		Response: &admissionResponse{
+			UID:      review.Request.UID,
+			Allowed:  resp.Allowed,
+			Warnings: resp.Warnings,
+		},
+	}
+	if len(resp.Patch) > 0 {
+		patchType := string(admissionv1.PatchTypeJSONPatch)
+		out.Response.Patch = resp.Patch
+		out.Response.PatchType = &patchType
+	}
+	if !resp.Allowed {
+		out.Response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    resp.Code,
+			Reason:  reasonForCode(resp.Code),
+			Message: resp.Message,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		log.Error(err, "Failed to write the admission response")
+	}
+}
+
+// reasonForCode gets the reason shown to the user for the status code of a
+// rejected request
+func reasonForCode(code int32) metav1.StatusReason {
+	switch code {
+	case http.StatusBadRequest:
+		return metav1.StatusReasonBadRequest
+	case http.StatusUnauthorized:
+		return metav1.StatusReasonUnauthorized
+	case http.StatusForbidden:
+		return metav1.StatusReasonForbidden
+	case http.StatusNotFound:
+		return metav1.StatusReasonNotFound
+	case http.StatusConflict:
+		return metav1.StatusReasonConflict
+	case http.StatusUnprocessableEntity:
+		return metav1.StatusReasonInvalid
+	case http.StatusInternalServerError:
+		return metav1.StatusReasonInternalError
+	}
+	return metav1.StatusReasonUnknown
+}
@@ -0,0 +1,53 @@
+package selinuxpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/review"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/server"
+)
+
+const (
+	authorWebhookPath = "/mutate-selinuxpolicy"
+)
+
+// AddAuthor registers the webhook that records the author of the policies
+// in the manager's webhook server.
+func AddAuthor(mgr manager.Manager) error {
+	return server.Register(mgr, authorWebhookPath, &review.Webhook{Handler: RecordAuthor})
+}
+
+// RecordAuthor records the user that changes the content of a policy in
+// its author annotation. The annotation can't be set by anyone else.
+func RecordAuthor(ctx context.Context, req *admissionv1.AdmissionRequest) review.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return review.Allowed()
+	}
+	policy := &selinuxv1alpha1.SelinuxPolicy{}
+	if err := json.Unmarshal(req.Object.Raw, policy); err != nil {
+		return review.Errored(400, fmt.Errorf("got a request but couldn't decode the policy"))
+	}
+
+	author := req.UserInfo.Username
+	if req.Operation == admissionv1.Update {
+		oldPolicy := &selinuxv1alpha1.SelinuxPolicy{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldPolicy); err != nil {
+			return review.Errored(400, fmt.Errorf("got a request but couldn't decode the old policy"))
+		}
+		if !contentChanged(oldPolicy, policy) {
+			// The content didn't change, so neither did the author
+			author = oldPolicy.Annotations[utils.AuthorAnnotation]
+		}
+	}
+	if policy.Annotations[utils.AuthorAnnotation] == author {
+		return review.Allowed()
+	}
+	return review.Patched(review.AnnotationPatch(policy.Annotations, utils.AuthorAnnotation, author))
+}
@@ -0,0 +1,124 @@
+// This webhook reviews the SelinuxPolicies that are created or updated.
+// Policies that break the cluster's constraints are rejected. Policies with
+// overly broad or dangerous rules are admitted with a warning, or rejected
+// if the lint rule they break is an error.
+
+package selinuxpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/constraint"
+	"github.com/JAORMX/selinux-operator/pkg/lint"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/review"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/server"
+)
+
+const (
+	webhookPath = "/validate-selinuxpolicy"
+)
+
+var log = logf.Log.WithName("webhook_selinuxpolicy")
+
+// ValidatePolicy reviews SelinuxPolicies before they're stored
+type ValidatePolicy struct {
+	client client.Client
+}
+
+// Add registers the webhook in the manager's webhook server.
+func Add(mgr manager.Manager) error {
+	validator := &ValidatePolicy{
+		client: mgr.GetClient(),
+	}
+	return server.Register(mgr, webhookPath, &review.Webhook{Handler: validator.Handle})
+}
+
+// Handle reviews a request for a SelinuxPolicy
+func (v *ValidatePolicy) Handle(ctx context.Context, req *admissionv1.AdmissionRequest) review.Response {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+
+	policyResource := metav1.GroupVersionResource{
+		Group:    selinuxv1alpha1.SchemeGroupVersion.Group,
+		Version:  selinuxv1alpha1.SchemeGroupVersion.Version,
+		Resource: "selinuxpolicies",
+	}
+	if req.Resource != policyResource {
+		reqLogger.Info("Got a request for the wrong resource.")
+		return review.Errored(400, fmt.Errorf("got a request for the wrong resource"))
+	}
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return review.Allowed()
+	}
+
+	policy := &selinuxv1alpha1.SelinuxPolicy{}
+	if err := json.Unmarshal(req.Object.Raw, policy); err != nil {
+		reqLogger.Info("ERROR: Unable to decode the policy")
+		return review.Errored(400, fmt.Errorf("got a request but couldn't decode the policy"))
+	}
+	// The name and namespace aren't always set in the object
+	policy.Name = req.Name
+	policy.Namespace = req.Namespace
+
+	// Updates that leave what gets installed as it is, such as the
+	// operator's own finalizer handling, are never held back.
+	if req.Operation == admissionv1.Update {
+		oldPolicy := &selinuxv1alpha1.SelinuxPolicy{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldPolicy); err == nil && !contentChanged(oldPolicy, policy) {
+			return review.Allowed()
+		}
+	}
+
+	violations, err := constraint.Check(ctx, v.client, policy)
+	if err != nil {
+		return review.Errored(500, err)
+	}
+	if len(violations) > 0 {
+		reqLogger.Info("The policy breaks the cluster's constraints", "violations", len(violations))
+		return review.Denied("the policy breaks the cluster's constraints: " + strings.Join(violations, "; "))
+	}
+
+	cfg, err := operatorconfig.Fetch(ctx, v.client)
+	if err != nil {
+		return review.Errored(500, err)
+	}
+	return v.lintPolicy(policy, cfg)
+}
+
+func (v *ValidatePolicy) lintPolicy(policy *selinuxv1alpha1.SelinuxPolicy, cfg *selinuxv1alpha1.SelinuxOperatorConfigSpec) review.Response {
+	// Precompiled policies can't be reviewed
+	if policy.Spec.BinaryPolicy != nil || policy.Spec.Policy == "" {
+		return review.Allowed()
+	}
+	findings, err := lint.Lint(policy.Spec.Policy, policy.Name, policy.Namespace, &cfg.Lint)
+	if err != nil {
+		// The controller reports why in the policy's status
+		return review.Allowed(fmt.Sprintf("the policy can't be parsed: %s", err))
+	}
+	errs, warnings := lint.Split(findings)
+	if len(errs) > 0 {
+		return review.Denied("the policy breaks lint rules: "+strings.Join(errs, "; "), warnings...)
+	}
+	return review.Allowed(warnings...)
+}
+
+// contentChanged returns true if the policy was changed in a way that
+// changes what gets installed on the nodes.
+func contentChanged(oldPolicy, policy *selinuxv1alpha1.SelinuxPolicy) bool {
+	return oldPolicy.Spec.Policy != policy.Spec.Policy ||
+		!reflect.DeepEqual(oldPolicy.Spec.BinaryPolicy, policy.Spec.BinaryPolicy) ||
+		oldPolicy.Spec.Mode != policy.Spec.Mode ||
+		!oldPolicy.Spec.PermissiveUntil.Equal(policy.Spec.PermissiveUntil) ||
+		oldPolicy.Spec.TemplateVersion != policy.Spec.TemplateVersion
+}
@@ -0,0 +1,112 @@
+// This webhook records who approves a SelinuxPolicy. Only the members of
+// the approver groups may create approvals, they can't approve their own
+// changes, and approvals can't be changed once they're created.
+
+package selinuxpolicyapproval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	selinuxv1alpha1 "github.com/JAORMX/selinux-operator/pkg/apis/selinux/v1alpha1"
+	"github.com/JAORMX/selinux-operator/pkg/controller/utils"
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/review"
+	"github.com/JAORMX/selinux-operator/pkg/webhook/server"
+)
+
+const (
+	webhookPath = "/mutate-selinuxpolicyapproval"
+)
+
+var log = logf.Log.WithName("webhook_selinuxpolicyapproval")
+
+// RecordApprover reviews SelinuxPolicyApprovals and records their approver
+type RecordApprover struct {
+	client client.Client
+}
+
+// Add registers the webhook in the manager's webhook server.
+func Add(mgr manager.Manager) error {
+	recorder := &RecordApprover{
+		client: mgr.GetClient(),
+	}
+	return server.Register(mgr, webhookPath, &review.Webhook{Handler: recorder.Handle})
+}
+
+// Handle handles requests for SelinuxPolicyApprovals
+func (r *RecordApprover) Handle(ctx context.Context, req *admissionv1.AdmissionRequest) review.Response {
+	reqLogger := log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+
+	approval := &selinuxv1alpha1.SelinuxPolicyApproval{}
+	if req.Operation == admissionv1.Create || req.Operation == admissionv1.Update {
+		if err := json.Unmarshal(req.Object.Raw, approval); err != nil {
+			return review.Errored(400, fmt.Errorf("got a request but couldn't decode the approval"))
+		}
+	}
+
+	switch req.Operation {
+	case admissionv1.Create:
+		msg, err := r.checkApprover(ctx, req, approval)
+		if err != nil {
+			return review.Errored(500, err)
+		}
+		if msg != "" {
+			reqLogger.Info("Rejected approval", "reason", msg)
+			return review.Denied(msg)
+		}
+		return review.Patched(review.AnnotationPatch(approval.Annotations, utils.ApproverAnnotation, req.UserInfo.Username))
+	case admissionv1.Update:
+		oldApproval := &selinuxv1alpha1.SelinuxPolicyApproval{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldApproval); err != nil {
+			return review.Errored(400, fmt.Errorf("got a request but couldn't decode the old approval"))
+		}
+		if approval.Spec != oldApproval.Spec {
+			return review.Denied("approvals can't be changed, create a new one instead")
+		}
+		approver := oldApproval.Annotations[utils.ApproverAnnotation]
+		if approval.Annotations[utils.ApproverAnnotation] != approver {
+			return review.Patched(review.AnnotationPatch(approval.Annotations, utils.ApproverAnnotation, approver))
+		}
+	}
+	return review.Allowed()
+}
+
+// checkApprover returns why the requesting user can't create the given
+// approval, or an empty string if they can.
+func (r *RecordApprover) checkApprover(ctx context.Context, req *admissionv1.AdmissionRequest, approval *selinuxv1alpha1.SelinuxPolicyApproval) (string, error) {
+	cfg, err := operatorconfig.Fetch(ctx, r.client)
+	if err != nil {
+		return "", err
+	}
+	isApprover := false
+	for _, group := range req.UserInfo.Groups {
+		if utils.SliceContainsString(cfg.Approval.ApproverGroups, group) {
+			isApprover = true
+			break
+		}
+	}
+	if !isApprover {
+		return "only the members of the approver groups can approve policies", nil
+	}
+
+	policy := &selinuxv1alpha1.SelinuxPolicy{}
+	err = r.client.Get(ctx, types.NamespacedName{Name: approval.Spec.PolicyName, Namespace: req.Namespace}, policy)
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("SelinuxPolicy '%s' doesn't exist", approval.Spec.PolicyName), nil
+	} else if err != nil {
+		return "", err
+	}
+	if policy.Annotations[utils.AuthorAnnotation] == req.UserInfo.Username {
+		return "policies can't be approved by the user that changed them", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,133 @@
+// Package server provides the server that the operator's webhooks are
+// served from.
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/JAORMX/selinux-operator/pkg/operatorconfig"
+)
+
+const webhookHost = "0.0.0.0"
+
+var log = logf.Log.WithName("webhook_server")
+
+var (
+	mu      sync.Mutex
+	servers = map[manager.Manager]*Server{}
+)
+
+type hook struct {
+	path    string
+	handler http.Handler
+}
+
+// Server serves the registered webhooks. It restarts itself whenever the
+// port or the certificate directory change in the operator settings.
+type Server struct {
+	mgr   manager.Manager
+	hooks []hook
+}
+
+// Register serves the given webhook at the given path. The server is added
+// to the manager the first time a webhook is registered.
+func Register(mgr manager.Manager, path string, handler http.Handler) error {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := servers[mgr]
+	if !ok {
+		s = &Server{mgr: mgr}
+		if err := mgr.Add(s); err != nil {
+			return err
+		}
+		servers[mgr] = s
+	}
+	s.hooks = append(s.hooks, hook{path: path, handler: handler})
+	return nil
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface. The
+// webhooks are served by every replica of the operator.
+func (*Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the server until the stop channel is closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	changes := operatorconfig.Subscribe()
+	for {
+		cfg := operatorconfig.Get()
+		if !waitForCertificate(cfg.WebhookCertDir, stop) {
+			return nil
+		}
+		srv := &webhook.Server{
+			Host:    webhookHost,
+			Port:    int(cfg.WebhookPort),
+			CertDir: cfg.WebhookCertDir,
+		}
+		if err := s.mgr.SetFields(srv); err != nil {
+			return err
+		}
+		for _, h := range s.hooks {
+			srv.Register(h.path, h.handler)
+		}
+
+		srvStop := make(chan struct{})
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- srv.Start(srvStop)
+		}()
+
+		restart := false
+		for !restart {
+			select {
+			case <-stop:
+				close(srvStop)
+				return <-errCh
+			case err := <-errCh:
+				return err
+			case <-changes:
+				newCfg := operatorconfig.Get()
+				restart = newCfg.WebhookPort != cfg.WebhookPort || newCfg.WebhookCertDir != cfg.WebhookCertDir
+			}
+		}
+
+		log.Info("Restarting the webhook server to apply the new settings")
+		close(srvStop)
+		if err := <-errCh; err != nil {
+			return err
+		}
+	}
+}
+
+// waitForCertificate waits until the certificate and key are in the given
+// directory, as the operator might still be issuing them. It returns false
+// if the stop channel was closed first.
+func waitForCertificate(certDir string, stop <-chan struct{}) bool {
+	logged := false
+	for {
+		_, certErr := os.Stat(filepath.Join(certDir, corev1.TLSCertKey))
+		_, keyErr := os.Stat(filepath.Join(certDir, corev1.TLSPrivateKeyKey))
+		if certErr == nil && keyErr == nil {
+			return true
+		}
+		if !logged {
+			log.Info("Waiting for the webhook server's certificate", "dir", certDir)
+			logged = true
+		}
+		select {
+		case <-stop:
+			return false
+		case <-time.After(time.Second):
+		}
+	}
+}
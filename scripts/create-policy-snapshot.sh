@@ -0,0 +1,48 @@
+#!/bin/bash
+# Creates the ConfigMap that describes the SELinux policy loaded on the
+# nodes, which the operator uses to check policies before installing them.
+#
+# Usage: create-policy-snapshot.sh <node> [namespace]
+
+set -e
+
+NODE=${1:?"usage: $0 <node> [namespace]"}
+NAMESPACE=${2:-openshift-selinux-operator}
+IMAGE=${IMAGE:-quay.io/jaosorior/udica}
+OUTDIR=$(mktemp -d)
+
+read -r -d '' DUMP_POLICY << 'EOS' || true
+import glob, sys, setools
+policy = setools.SELinuxPolicy(sorted(glob.glob("/host/etc/selinux/targeted/policy/policy.*"))[-1])
+what = sys.argv[1]
+if what == "types":
+    for t in policy.types():
+        print(t)
+elif what == "attributes":
+    for a in policy.typeattributes():
+        print(a)
+elif what == "attributeTypes":
+    for a in policy.typeattributes():
+        print(a, " ".join(sorted(str(t) for t in a.expand())))
+elif what == "classes":
+    for c in policy.classes():
+        perms = set(c.perms)
+        try:
+            perms |= set(c.common.perms)
+        except setools.exception.NoCommon:
+            pass
+        print(c, " ".join(sorted(perms)))
+EOS
+
+for what in types attributes attributeTypes classes; do
+    oc debug "node/${NODE}" --image="${IMAGE}" -- python3 -c "${DUMP_POLICY}" "${what}" > "${OUTDIR}/${what}"
+done
+
+oc create configmap selinux-policy-snapshot -n "${NAMESPACE}" \
+    --from-file=types="${OUTDIR}/types" \
+    --from-file=attributes="${OUTDIR}/attributes" \
+    --from-file=attributeTypes="${OUTDIR}/attributeTypes" \
+    --from-file=classes="${OUTDIR}/classes" \
+    --dry-run -o yaml | oc apply -f -
+
+rm -rf "${OUTDIR}"